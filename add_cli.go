@@ -0,0 +1,102 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HandleAddCommand creates a new branch and worktree without the TUI.
+// It mirrors the "a" flow in the TUI: generate the path, create the
+// branch and copy configured files.
+func HandleAddCommand(args []string) {
+	fs := flag.NewFlagSet("add", flag.ContinueOnError)
+	base := fs.String("base", "", "start the new branch from this ref instead of the default branch")
+	path := fs.String("path", "", "create the worktree at this directory instead of the generated path")
+	noCopy := fs.Bool("no-copy", false, "do not copy configured copy_files into the worktree")
+	cd := fs.Bool("cd", false, "change the shell to the new worktree (requires the shell wrapper)")
+	fs.Usage = printAddHelp
+
+	positional, err := parseCommandArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return
+		}
+		os.Exit(exitUsage)
+	}
+	if len(positional) != 1 {
+		printAddHelp()
+		os.Exit(exitUsage)
+	}
+
+	repo := currentRepository()
+	if *base == "" {
+		*base = defaultBase(repo)
+	}
+
+	worktreePath, err := addWorktreeForBranch(repo, positional[0], *path, AddOptions{Base: *base, NoCopy: *noCopy})
+	if err != nil {
+		fail(exitError, "%v", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "✓ Worktree created: %s\n", worktreePath)
+	fmt.Println(worktreePath)
+
+	if *cd {
+		if err := writeCdPath(worktreePath); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to write cd path: %v\n", err)
+		}
+	}
+}
+
+func printAddHelp() {
+	fmt.Println("Usage: worktree-util add <branch> [--base <ref>] [--path <dir>] [--no-copy] [--cd]")
+	fmt.Println("\nCreates a new branch and a worktree for it, like pressing 'a' in the TUI.")
+	fmt.Println("The worktree path is printed on stdout.")
+	fmt.Println("\nOptions:")
+	fmt.Println("  --base <ref>    Start the new branch from <ref> (default: origin/HEAD,")
+	fmt.Println("                  or HEAD when the repository has no default remote branch)")
+	fmt.Println("  --path <dir>    Use <dir> instead of the auto-generated path")
+	fmt.Println("  --no-copy       Do not copy configured copy_files")
+	fmt.Println("  --cd            Change to the new worktree (requires the shell wrapper)")
+}
+
+// addWorktreeForBranch creates a worktree with a new branch and returns its path.
+// An empty path means the path is generated from the branch name.
+func addWorktreeForBranch(repo *Repository, branch, path string, opts AddOptions) (string, error) {
+	branch = strings.TrimSpace(branch)
+	if branch == "" {
+		return "", fmt.Errorf("branch name cannot be empty")
+	}
+
+	if path == "" {
+		generated, err := repo.GenerateWorktreePath(branch)
+		if err != nil {
+			return "", err
+		}
+		path = generated
+	} else {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return "", err
+		}
+		path = abs
+	}
+
+	if err := repo.AddWorktreeWithOptions(path, branch, true, opts); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// defaultBase returns the ref new branches start from: the remote default
+// branch when origin/HEAD is set, HEAD otherwise
+func defaultBase(repo *Repository) string {
+	if branch, err := repo.DefaultBranch(); err == nil && branch != "" {
+		return branch
+	}
+	return "HEAD"
+}
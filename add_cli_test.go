@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAddWorktreeForBranch(t *testing.T) {
+	withConfig(t, &Config{WorktreeDir: ".worktrees", CopyFiles: []string{".env"}})
+
+	dir := newTestRepo(t)
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("ENV=test"), 0644); err != nil {
+		t.Fatalf("Failed to create .env: %v", err)
+	}
+	repo := NewRepository(dir, ExecRunner{})
+
+	path, err := addWorktreeForBranch(repo, "feature/login", "", AddOptions{})
+	if err != nil {
+		t.Fatalf("addWorktreeForBranch() error = %v", err)
+	}
+
+	expected := filepath.Join(dir, ".worktrees", "feature-login")
+	if path != expected {
+		t.Errorf("addWorktreeForBranch() path = %v, want %v", path, expected)
+	}
+	if branch := runGit(t, path, "rev-parse", "--abbrev-ref", "HEAD"); branch != "feature/login" {
+		t.Errorf("worktree is on branch %q, want feature/login", branch)
+	}
+	if _, err := os.Stat(filepath.Join(path, ".env")); err != nil {
+		t.Errorf("configured file was not copied: %v", err)
+	}
+
+	// Creating the same branch again must fail
+	if _, err := addWorktreeForBranch(repo, "feature/login", "", AddOptions{}); err == nil {
+		t.Error("addWorktreeForBranch() should fail for an existing worktree path")
+	}
+}
+
+func TestAddWorktreeForBranch_BaseAndPath(t *testing.T) {
+	withConfig(t, &Config{WorktreeDir: ".worktrees", CopyFiles: []string{".env"}})
+
+	dir := newTestRepo(t)
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("ENV=test"), 0644); err != nil {
+		t.Fatalf("Failed to create .env: %v", err)
+	}
+	base := runGit(t, dir, "rev-parse", "HEAD")
+	runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "second commit")
+
+	customPath := filepath.Join(t.TempDir(), "custom")
+	path, err := addWorktreeForBranch(NewRepository(dir, ExecRunner{}), "hotfix", customPath, AddOptions{Base: base, NoCopy: true})
+	if err != nil {
+		t.Fatalf("addWorktreeForBranch() error = %v", err)
+	}
+
+	if path != customPath {
+		t.Errorf("addWorktreeForBranch() path = %v, want %v", path, customPath)
+	}
+	if head := runGit(t, path, "rev-parse", "HEAD"); head != base {
+		t.Errorf("worktree HEAD = %v, want base %v", head, base)
+	}
+	if _, err := os.Stat(filepath.Join(path, ".env")); !os.IsNotExist(err) {
+		t.Error("configured file should not be copied with NoCopy")
+	}
+}
+
+func TestAddWorktreeForBranch_EmptyBranch(t *testing.T) {
+	_, err := addWorktreeForBranch(NewRepository(t.TempDir(), newFakeRunner()), "  ", "", AddOptions{})
+	if err == nil || !strings.Contains(err.Error(), "cannot be empty") {
+		t.Errorf("addWorktreeForBranch() should reject empty branch, got: %v", err)
+	}
+}
+
+func TestDefaultBase(t *testing.T) {
+	fake := newFakeRunner().onError("symbolic-ref --quiet --short refs/remotes/origin/HEAD", "")
+	if base := defaultBase(NewRepository("/repo", fake)); base != "HEAD" {
+		t.Errorf("defaultBase() without origin/HEAD = %v, want HEAD", base)
+	}
+
+	fake = newFakeRunner().on("symbolic-ref --quiet --short refs/remotes/origin/HEAD", "origin/main\n")
+	if base := defaultBase(NewRepository("/repo", fake)); base != "origin/main" {
+		t.Errorf("defaultBase() = %v, want origin/main", base)
+	}
+}
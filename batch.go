@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// batchAction is an operation applied to all marked worktrees at once
+type batchAction int
+
+const (
+	batchRemove batchAction = iota
+	batchTrash
+	batchPrune
+	batchLock
+	batchRun
+)
+
+// String names the action for the confirmation and progress screens
+func (a batchAction) String() string {
+	switch a {
+	case batchRemove:
+		return "Remove"
+	case batchTrash:
+		return "Move to trash"
+	case batchPrune:
+		return "Prune"
+	case batchLock:
+		return "Lock"
+	case batchRun:
+		return "Run command"
+	}
+	return "Unknown"
+}
+
+// batchWorkers bounds the number of worktrees processed at the same time
+const batchWorkers = 4
+
+// batchSkipReason tells why wt is left out of action, empty if it is included
+func batchSkipReason(action batchAction, wt Worktree) string {
+	switch action {
+	case batchRemove, batchTrash:
+		if wt.IsBare {
+			return "bare repository"
+		}
+		if wt.IsMain {
+			return "main worktree"
+		}
+		if wt.Locked {
+			return lockedError(wt).Error()
+		}
+		if action == batchTrash && wt.Prunable {
+			return "directory is gone, nothing to keep"
+		}
+	case batchPrune:
+		if !wt.Prunable {
+			return "not stale"
+		}
+	case batchLock:
+		if wt.IsBare {
+			return "bare repository"
+		}
+		if wt.IsMain {
+			return "main worktree"
+		}
+		if wt.Locked {
+			return "already locked"
+		}
+	case batchRun:
+		if wt.IsBare || wt.Prunable {
+			return "no directory to run in"
+		}
+	}
+	return ""
+}
+
+// splitBatchTargets divides worktrees into those action applies to and
+// "path: reason" lines for the skipped ones
+func splitBatchTargets(action batchAction, worktrees []Worktree) ([]Worktree, []string) {
+	var targets []Worktree
+	var skipped []string
+	for _, wt := range worktrees {
+		if reason := batchSkipReason(action, wt); reason != "" {
+			skipped = append(skipped, fmt.Sprintf("%s: %s", wt.Path, reason))
+			continue
+		}
+		targets = append(targets, wt)
+	}
+	return targets, skipped
+}
+
+// batchResult is the outcome of a batch action for one worktree
+type batchResult struct {
+	Path   string
+	Output string // Last line of the command output, or what happened
+	Err    error
+}
+
+type batchDoneMsg batchResult
+
+// runBatch runs action on every target concurrently, at most batchWorkers at
+// a time; each worktree reports its own batchDoneMsg
+func runBatch(repo *Repository, action batchAction, targets []Worktree, command string) tea.Cmd {
+	slots := make(chan struct{}, batchWorkers)
+	cmds := make([]tea.Cmd, 0, len(targets))
+	for _, wt := range targets {
+		cmds = append(cmds, func() tea.Msg {
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			output, err := runBatchItem(repo, action, wt, command)
+			return batchDoneMsg{Path: wt.Path, Output: output, Err: err}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+// runBatchItem applies action to a single worktree
+func runBatchItem(repo *Repository, action batchAction, wt Worktree, command string) (string, error) {
+	switch action {
+	case batchRemove, batchPrune:
+		return "removed", repo.RemoveWorktree(wt.Path, false)
+	case batchTrash:
+		id, err := repo.RemoveWorktreeWithOptions(wt.Path, RemoveOptions{Trash: true})
+		return "moved to trash as " + id, err
+	case batchLock:
+		return "locked", repo.LockWorktree(wt.Path, "")
+	case batchRun:
+		return runShellCommand(wt.Path, command)
+	}
+	return "", fmt.Errorf("unknown batch action")
+}
+
+// runShellCommand runs command with sh in dir and returns the last line of
+// its output
+func runShellCommand(dir, command string) (string, error) {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+
+	lines := splitLines(strings.TrimSpace(string(out)))
+	last := ""
+	if len(lines) > 0 {
+		last = lines[len(lines)-1]
+	}
+	if err != nil {
+		if last != "" {
+			return last, fmt.Errorf("%v: %s", err, last)
+		}
+		return last, err
+	}
+	return last, nil
+}
+
+// markDelegate renders the worktree list, showing which items are marked
+type markDelegate struct {
+	list.DefaultDelegate
+	marked map[string]bool
+}
+
+// Render draws marked worktrees with a marker in front of the title
+func (d markDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	if wt, ok := item.(Worktree); ok && d.marked[wt.Path] {
+		item = markedWorktree{wt}
+	}
+	d.DefaultDelegate.Render(w, m, index, item)
+}
+
+// markedWorktree is a worktree marked for a batch action
+type markedWorktree struct {
+	Worktree
+}
+
+// Title returns the worktree title with the mark
+func (w markedWorktree) Title() string {
+	return "◉ " + w.Worktree.Title()
+}
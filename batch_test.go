@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSplitBatchTargets(t *testing.T) {
+	worktrees := []Worktree{
+		{Path: "/repo", Branch: "main", IsMain: true},
+		{Path: "/repo/.worktrees/a", Branch: "a"},
+		{Path: "/repo/.worktrees/b", Branch: "b", Locked: true, LockReason: "usb drive"},
+		{Path: "/repo/.worktrees/gone", Branch: "gone", Prunable: true},
+	}
+
+	tests := []struct {
+		action  batchAction
+		targets []string
+		skipped int
+	}{
+		{action: batchRemove, targets: []string{"/repo/.worktrees/a", "/repo/.worktrees/gone"}, skipped: 2},
+		{action: batchTrash, targets: []string{"/repo/.worktrees/a"}, skipped: 3},
+		{action: batchPrune, targets: []string{"/repo/.worktrees/gone"}, skipped: 3},
+		{action: batchLock, targets: []string{"/repo/.worktrees/a", "/repo/.worktrees/gone"}, skipped: 2},
+		{action: batchRun, targets: []string{"/repo", "/repo/.worktrees/a", "/repo/.worktrees/b"}, skipped: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.action.String(), func(t *testing.T) {
+			targets, skipped := splitBatchTargets(tt.action, worktrees)
+			var paths []string
+			for _, wt := range targets {
+				paths = append(paths, wt.Path)
+			}
+			if !reflect.DeepEqual(paths, tt.targets) {
+				t.Errorf("targets = %v, want %v", paths, tt.targets)
+			}
+			if len(skipped) != tt.skipped {
+				t.Errorf("skipped = %v, want %d entries", skipped, tt.skipped)
+			}
+		})
+	}
+
+	_, skipped := splitBatchTargets(batchRemove, worktrees[2:3])
+	if len(skipped) != 1 || !strings.Contains(skipped[0], "worktree is locked: usb drive") {
+		t.Errorf("skipped = %v, should give the lock reason", skipped)
+	}
+
+	_, skipped = splitBatchTargets(batchLock, []Worktree{{Path: "/repo.git", IsBare: true}})
+	if len(skipped) != 1 || skipped[0] != "/repo.git: bare repository" {
+		t.Errorf("skipped = %v, should name the bare repository", skipped)
+	}
+}
+
+func TestRunShellCommand(t *testing.T) {
+	dir := t.TempDir()
+
+	output, err := runShellCommand(dir, "echo first; pwd")
+	if err != nil {
+		t.Fatalf("runShellCommand() error = %v", err)
+	}
+	if resolved, _ := filepath.EvalSymlinks(dir); output != dir && output != resolved {
+		t.Errorf("runShellCommand() = %q, want the last line, the directory %q", output, dir)
+	}
+
+	if _, err := runShellCommand(dir, "echo broken; exit 3"); err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("runShellCommand() error = %v, want the exit status with the output", err)
+	}
+}
+
+func TestMarkDelegate(t *testing.T) {
+	marked := map[string]bool{"/repo/.worktrees/a": true}
+	delegate := markDelegate{DefaultDelegate: list.NewDefaultDelegate(), marked: marked}
+	l := list.New([]list.Item{Worktree{Path: "/repo/.worktrees/a"}, Worktree{Path: "/repo/.worktrees/b"}}, delegate, 80, 20)
+
+	var out bytes.Buffer
+	delegate.Render(&out, l, 0, l.Items()[0])
+	if !strings.Contains(out.String(), "◉ 📁 /repo/.worktrees/a") {
+		t.Errorf("marked worktree should be rendered with a marker, got %q", out.String())
+	}
+
+	out.Reset()
+	delegate.Render(&out, l, 1, l.Items()[1])
+	if strings.Contains(out.String(), "◉") {
+		t.Errorf("unmarked worktree should have no marker, got %q", out.String())
+	}
+}
+
+func TestBatch_MarkAndRemove(t *testing.T) {
+	dir := newTestRepo(t)
+	var worktrees []Worktree
+	worktrees = append(worktrees, Worktree{Path: dir, Branch: "main", IsMain: true})
+	for _, branch := range []string{"a", "b", "c"} {
+		worktrees = append(worktrees, Worktree{Path: addTestWorktree(t, dir, branch), Branch: branch})
+	}
+	m := initialModel(NewRepository(dir, ExecRunner{}))
+	m.setWorktrees(worktrees)
+
+	// Mark main, a and b; space moves down after marking
+	for i := 0; i < 3; i++ {
+		updated, _ := m.updateList(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+		m = updated.(model)
+	}
+	if len(m.marked) != 3 || !strings.Contains(m.list.Title, "3 marked") {
+		t.Fatalf("marked = %v, title = %q", m.marked, m.list.Title)
+	}
+
+	updated, _ := m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	m = updated.(model)
+	if m.mode != modeBatchConfirm || len(m.batchTargets) != 2 || len(m.batchSkipped) != 1 {
+		t.Fatalf("d should confirm removing 2 worktrees and skip main, mode = %v, targets = %v", m.mode, m.batchTargets)
+	}
+
+	updated, cmd := m.updateBatchConfirm(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	m = updated.(model)
+	if m.mode != modeBatchProgress || !m.batchRunning() {
+		t.Fatalf("confirming should start the batch, mode = %v", m.mode)
+	}
+
+	// Run the batch commands and deliver their results
+	for _, c := range cmd().(tea.BatchMsg) {
+		if cmds, ok := c().(tea.BatchMsg); ok {
+			for _, item := range cmds {
+				if msg, ok := item().(batchDoneMsg); ok {
+					updated, _ = m.Update(msg)
+					m = updated.(model)
+				}
+			}
+		}
+	}
+	if m.batchRunning() || len(m.batchResults) != 2 {
+		t.Fatalf("all results should be in, got %v", m.batchResults)
+	}
+	for _, result := range m.batchResults {
+		if result.Err != nil {
+			t.Errorf("removing %s failed: %v", result.Path, result.Err)
+		}
+	}
+	if len(m.marked) != 0 {
+		t.Error("marks should be cleared after the batch")
+	}
+	if out := runGit(t, dir, "worktree", "list"); strings.Contains(out, "/a ") || strings.Contains(out, "/b ") || !strings.Contains(out, "/c ") {
+		t.Errorf("a and b should be removed, c kept:\n%s", out)
+	}
+
+	updated, _ = m.updateBatchProgress(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if m.mode != modeList || m.message != "Remove: 2 succeeded, 0 failed" {
+		t.Errorf("enter should return to the list with a summary, mode = %v, message = %q", m.mode, m.message)
+	}
+}
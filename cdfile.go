@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// cdFile is the file the shell wrapper reads the target directory from.
+// It is created by the wrapper (with mktemp) and passed via --cd-file, so
+// every invocation gets its own private file.
+var cdFile string
+
+// parseGlobalFlags removes global flags from the front of args
+func parseGlobalFlags(args []string) ([]string, error) {
+	for len(args) > 0 {
+		switch {
+		case args[0] == "--cd-file":
+			if len(args) < 2 || args[1] == "" {
+				return nil, fmt.Errorf("--cd-file requires a file path")
+			}
+			cdFile = args[1]
+			args = args[2:]
+		case strings.HasPrefix(args[0], "--cd-file="):
+			cdFile = strings.TrimPrefix(args[0], "--cd-file=")
+			if cdFile == "" {
+				return nil, fmt.Errorf("--cd-file requires a file path")
+			}
+			args = args[1:]
+		default:
+			return args, nil
+		}
+	}
+	return args, nil
+}
+
+// writeCdPath hands the directory to cd into over to the shell wrapper.
+// Without --cd-file there is no wrapper listening, so nothing is written.
+func writeCdPath(path string) error {
+	if cdFile == "" {
+		return fmt.Errorf("no --cd-file given; set up the shell wrapper to change directories")
+	}
+
+	// Never follow a symlink or write into something that isn't a plain file
+	if info, err := os.Lstat(cdFile); err == nil && !info.Mode().IsRegular() {
+		return fmt.Errorf("cd file %s is not a regular file", cdFile)
+	}
+
+	f, err := os.OpenFile(cdFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(path); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
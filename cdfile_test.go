@@ -0,0 +1,113 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseGlobalFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+		cdFile   string
+		wantErr  bool
+	}{
+		{
+			name:     "no global flags",
+			args:     []string{"list", "--format", "json"},
+			expected: []string{"list", "--format", "json"},
+		},
+		{
+			name:     "separate value",
+			args:     []string{"--cd-file", "/tmp/x", "add", "feature"},
+			expected: []string{"add", "feature"},
+			cdFile:   "/tmp/x",
+		},
+		{
+			name:     "equals value",
+			args:     []string{"--cd-file=/tmp/y"},
+			expected: []string{},
+			cdFile:   "/tmp/y",
+		},
+		{
+			name:    "missing value",
+			args:    []string{"--cd-file"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			originalCdFile := cdFile
+			defer func() { cdFile = originalCdFile }()
+			cdFile = ""
+
+			args, err := parseGlobalFlags(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("parseGlobalFlags() should return an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseGlobalFlags() error = %v", err)
+			}
+			if !reflect.DeepEqual(args, tt.expected) {
+				t.Errorf("parseGlobalFlags() = %v, want %v", args, tt.expected)
+			}
+			if cdFile != tt.cdFile {
+				t.Errorf("cdFile = %q, want %q", cdFile, tt.cdFile)
+			}
+		})
+	}
+}
+
+func TestWriteCdPath(t *testing.T) {
+	originalCdFile := cdFile
+	defer func() { cdFile = originalCdFile }()
+
+	cdFile = ""
+	if err := writeCdPath("/some/path"); err == nil {
+		t.Error("writeCdPath() without --cd-file should return an error")
+	}
+
+	cdFile = filepath.Join(t.TempDir(), "cd")
+	if err := os.WriteFile(cdFile, []byte("stale content that is longer"), 0600); err != nil {
+		t.Fatalf("Failed to create cd file: %v", err)
+	}
+	if err := writeCdPath("/some/path"); err != nil {
+		t.Fatalf("writeCdPath() error = %v", err)
+	}
+	content, err := os.ReadFile(cdFile)
+	if err != nil {
+		t.Fatalf("Failed to read cd file: %v", err)
+	}
+	if string(content) != "/some/path" {
+		t.Errorf("cd file content = %q, want /some/path", string(content))
+	}
+}
+
+func TestWriteCdPath_RefusesSymlink(t *testing.T) {
+	originalCdFile := cdFile
+	defer func() { cdFile = originalCdFile }()
+
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target")
+	if err := os.WriteFile(target, []byte("keep"), 0600); err != nil {
+		t.Fatalf("Failed to create target: %v", err)
+	}
+	cdFile = filepath.Join(dir, "link")
+	if err := os.Symlink(target, cdFile); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	if err := writeCdPath("/some/path"); err == nil {
+		t.Error("writeCdPath() should refuse to write through a symlink")
+	}
+	if content, _ := os.ReadFile(target); string(content) != "keep" {
+		t.Errorf("symlink target was modified: %q", string(content))
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// HandleCheckoutCommand creates (or reuses) a worktree for an existing
+// local or remote branch, tag or commit without the TUI
+func HandleCheckoutCommand(args []string) {
+	fs := flag.NewFlagSet("checkout", flag.ContinueOnError)
+	detach := fs.Bool("detach", false, "check out a detached HEAD even if the ref is a branch")
+	cd := fs.Bool("cd", false, "change the shell to the worktree (requires the shell wrapper)")
+	fs.Usage = printCheckoutHelp
+
+	positional, err := parseCommandArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return
+		}
+		os.Exit(exitUsage)
+	}
+	if len(positional) != 1 {
+		printCheckoutHelp()
+		os.Exit(exitUsage)
+	}
+
+	branch := positional[0]
+	checkout := currentRepository().CheckoutBranchWorktree
+	if *detach {
+		checkout = currentRepository().CheckoutDetachedWorktree
+	}
+	path, created, err := checkout(branch)
+	if err != nil {
+		fail(exitError, "%v", err)
+	}
+
+	if created {
+		fmt.Fprintf(os.Stderr, "✓ Worktree created from '%s': %s\n", branch, path)
+	} else {
+		fmt.Fprintf(os.Stderr, "✓ Worktree already exists for '%s': %s\n", branch, path)
+	}
+	fmt.Println(path)
+
+	if *cd {
+		if err := writeCdPath(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to write cd path: %v\n", err)
+		}
+	}
+}
+
+func printCheckoutHelp() {
+	fmt.Println("Usage: worktree-util checkout <branch|remote/branch|tag|commit> [--detach] [--cd]")
+	fmt.Println("\nCreates a worktree for an existing local or remote branch, like pressing 'c'")
+	fmt.Println("in the TUI. If a worktree for the branch already exists it is reused.")
+	fmt.Println("Tags and commits are checked out with a detached HEAD into tag-<name> or")
+	fmt.Println("commit-<sha> directories.")
+	fmt.Println("The worktree path is printed on stdout; whether it was created or reused")
+	fmt.Println("is reported on stderr.")
+	fmt.Println("\nOptions:")
+	fmt.Println("  --detach    Check out a detached HEAD even if the ref is a branch")
+	fmt.Println("  --cd        Change to the worktree (requires the shell wrapper)")
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestParseCommandArgs(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		positional []string
+		base       string
+		force      bool
+	}{
+		{
+			name:       "flags after positional",
+			args:       []string{"feature", "--base", "main", "--force"},
+			positional: []string{"feature"},
+			base:       "main",
+			force:      true,
+		},
+		{
+			name:       "flags before positional",
+			args:       []string{"--base=develop", "feature", "other"},
+			positional: []string{"feature", "other"},
+			base:       "develop",
+		},
+		{
+			name:       "double dash stops flag parsing",
+			args:       []string{"--force", "--", "--not-a-flag"},
+			positional: []string{"--not-a-flag"},
+			force:      true,
+		},
+		{
+			name: "no arguments",
+			args: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			base := fs.String("base", "", "")
+			force := fs.Bool("force", false, "")
+
+			positional, err := parseCommandArgs(fs, tt.args)
+			if err != nil {
+				t.Fatalf("parseCommandArgs() error = %v", err)
+			}
+			if len(positional) != 0 || len(tt.positional) != 0 {
+				if !reflect.DeepEqual(positional, tt.positional) {
+					t.Errorf("positional = %v, want %v", positional, tt.positional)
+				}
+			}
+			if *base != tt.base {
+				t.Errorf("base = %q, want %q", *base, tt.base)
+			}
+			if *force != tt.force {
+				t.Errorf("force = %v, want %v", *force, tt.force)
+			}
+		})
+	}
+}
+
+func TestParseCommandArgs_UnknownFlag(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	if _, err := parseCommandArgs(fs, []string{"feature", "--nope"}); err == nil {
+		t.Error("parseCommandArgs() should error for unknown flag")
+	}
+}
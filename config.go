@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -11,6 +12,42 @@ import (
 type Config struct {
 	WorktreeDir string   `yaml:"worktree_dir"`
 	CopyFiles   []string `yaml:"copy_files"`
+	// PullRequestRefs maps a remote name to the ref style of its hosting
+	// service, "github" (the default) or "gitlab"
+	PullRequestRefs map[string]string `yaml:"pull_request_refs,omitempty"`
+	// PreferredRemotes decides which remote to track when a branch name
+	// exists on several remotes, first match wins
+	PreferredRemotes []string `yaml:"preferred_remotes,omitempty"`
+	// FetchRemotes limits the remotes fetched from the checkout picker,
+	// empty means all remotes
+	FetchRemotes []string `yaml:"fetch_remotes,omitempty"`
+	// FetchOnCheckout fetches every time the checkout picker is opened
+	FetchOnCheckout bool `yaml:"fetch_on_checkout,omitempty"`
+	// DeleteBranch preselects deleting the local branch with its worktree
+	DeleteBranch bool `yaml:"delete_branch,omitempty"`
+	// DeleteRemoteBranch preselects deleting the upstream branch with its worktree
+	DeleteRemoteBranch bool `yaml:"delete_remote_branch,omitempty"`
+}
+
+// pullRequestRefFormats are the ref names under which hosting services
+// publish the head of a pull (or merge) request
+var pullRequestRefFormats = map[string]string{
+	"github": "refs/pull/%d/head",
+	"gitlab": "refs/merge-requests/%d/head",
+}
+
+// PullRequestRef returns the ref holding pull request number on remote
+func (c *Config) PullRequestRef(remote string, number int) (string, error) {
+	style := "github"
+	if c != nil && c.PullRequestRefs[remote] != "" {
+		style = c.PullRequestRefs[remote]
+	}
+
+	format, ok := pullRequestRefFormats[style]
+	if !ok {
+		return "", fmt.Errorf("unknown pull request ref style '%s' for remote '%s' (use github or gitlab)", style, remote)
+	}
+	return fmt.Sprintf(format, number), nil
 }
 
 // DefaultConfig returns the default configuration
@@ -3,8 +3,18 @@ package main
 import (
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 )
 
+// configKeys are the keys understood by config get
+var configKeys = []string{"worktree_dir", "copy_files", "preferred_remotes", "fetch_remotes", "fetch_on_checkout", "delete_branch", "delete_remote_branch", "pull_request_refs"}
+
+// configSetKeys are the keys understood by config set; copy_files has its
+// own commands and pull_request_refs is edited in the file
+var configSetKeys = []string{"worktree_dir", "preferred_remotes", "fetch_remotes", "fetch_on_checkout", "delete_branch", "delete_remote_branch"}
+
 // HandleConfigCommand handles all config-related CLI commands
 func HandleConfigCommand(args []string) {
 	if len(args) == 0 {
@@ -22,14 +32,14 @@ func HandleConfigCommand(args []string) {
 	case "set":
 		if len(subArgs) < 2 {
 			fmt.Println("Usage: worktree-util config set <key> <value>")
-			fmt.Println("Available keys: worktree_dir")
+			fmt.Println("Available keys: " + strings.Join(configSetKeys, ", "))
 			os.Exit(1)
 		}
 		setConfig(subArgs[0], subArgs[1])
 	case "get":
 		if len(subArgs) < 1 {
 			fmt.Println("Usage: worktree-util config get <key>")
-			fmt.Println("Available keys: worktree_dir, copy_files")
+			fmt.Println("Available keys: " + strings.Join(configKeys, ", "))
 			os.Exit(1)
 		}
 		getConfig(subArgs[0])
@@ -82,6 +92,21 @@ func showConfig() {
 			fmt.Printf("    - %s\n", file)
 		}
 	}
+	if len(config.PreferredRemotes) > 0 {
+		fmt.Printf("  preferred_remotes: %s\n", strings.Join(config.PreferredRemotes, ", "))
+	}
+	if len(config.FetchRemotes) > 0 {
+		fmt.Printf("  fetch_remotes: %s\n", strings.Join(config.FetchRemotes, ", "))
+	}
+	fmt.Printf("  fetch_on_checkout: %t\n", config.FetchOnCheckout)
+	fmt.Printf("  delete_branch: %t\n", config.DeleteBranch)
+	fmt.Printf("  delete_remote_branch: %t\n", config.DeleteRemoteBranch)
+	if len(config.PullRequestRefs) > 0 {
+		fmt.Println("  pull_request_refs:")
+		for remote, style := range config.PullRequestRefs {
+			fmt.Printf("    %s: %s\n", remote, style)
+		}
+	}
 }
 
 func initConfig() {
@@ -106,9 +131,19 @@ func setConfig(key, value string) {
 	switch key {
 	case "worktree_dir":
 		config.WorktreeDir = value
+	case "preferred_remotes":
+		config.PreferredRemotes = splitConfigList(value)
+	case "fetch_remotes":
+		config.FetchRemotes = splitConfigList(value)
+	case "fetch_on_checkout":
+		config.FetchOnCheckout = parseConfigBool(key, value)
+	case "delete_branch":
+		config.DeleteBranch = parseConfigBool(key, value)
+	case "delete_remote_branch":
+		config.DeleteRemoteBranch = parseConfigBool(key, value)
 	default:
 		fmt.Printf("Unknown config key: %s\n", key)
-		fmt.Println("Available keys: worktree_dir")
+		fmt.Println("Available keys: " + strings.Join(configSetKeys, ", "))
 		os.Exit(1)
 	}
 
@@ -138,11 +173,62 @@ func getConfig(key string) {
 				fmt.Println(file)
 			}
 		}
+	case "preferred_remotes":
+		printConfigList(config.PreferredRemotes)
+	case "fetch_remotes":
+		printConfigList(config.FetchRemotes)
+	case "fetch_on_checkout":
+		fmt.Println(config.FetchOnCheckout)
+	case "delete_branch":
+		fmt.Println(config.DeleteBranch)
+	case "delete_remote_branch":
+		fmt.Println(config.DeleteRemoteBranch)
+	case "pull_request_refs":
+		remotes := make([]string, 0, len(config.PullRequestRefs))
+		for remote := range config.PullRequestRefs {
+			remotes = append(remotes, remote)
+		}
+		sort.Strings(remotes)
+		for _, remote := range remotes {
+			fmt.Printf("%s: %s\n", remote, config.PullRequestRefs[remote])
+		}
 	default:
 		fmt.Printf("Unknown config key: %s\n", key)
-		fmt.Println("Available keys: worktree_dir, copy_files")
+		fmt.Println("Available keys: " + strings.Join(configKeys, ", "))
+		os.Exit(1)
+	}
+}
+
+// parseConfigBool parses a true/false value, exiting on anything else
+func parseConfigBool(key, value string) bool {
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		fmt.Printf("Invalid value for %s: %s (use true or false)\n", key, value)
 		os.Exit(1)
 	}
+	return enabled
+}
+
+// splitConfigList parses a comma-separated value such as "upstream,origin"
+func splitConfigList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// printConfigList prints one item per line, or (none)
+func printConfigList(items []string) {
+	if len(items) == 0 {
+		fmt.Println("(none)")
+		return
+	}
+	for _, item := range items {
+		fmt.Println(item)
+	}
 }
 
 func addCopyFile(file string) {
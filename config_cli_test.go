@@ -3,6 +3,7 @@ package main
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -147,3 +148,20 @@ func TestInitConfig(t *testing.T) {
 		t.Errorf("Expected default worktree_dir '.worktrees', got '%s'", config.WorktreeDir)
 	}
 }
+
+func TestSetConfig_PreferredRemotes(t *testing.T) {
+	tempHome := t.TempDir()
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", tempHome)
+
+	setConfig("preferred_remotes", "upstream, origin")
+
+	loadedConfig, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if !reflect.DeepEqual(loadedConfig.PreferredRemotes, []string{"upstream", "origin"}) {
+		t.Errorf("Expected preferred_remotes [upstream origin], got %v", loadedConfig.PreferredRemotes)
+	}
+}
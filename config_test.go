@@ -137,3 +137,29 @@ func TestSaveConfig(t *testing.T) {
 		t.Errorf("Saved CopyFiles length = %v, want %v", len(loadedConfig.CopyFiles), len(config.CopyFiles))
 	}
 }
+
+func TestPullRequestRef(t *testing.T) {
+	config := &Config{PullRequestRefs: map[string]string{"gitlab": "gitlab", "broken": "bitbucket"}}
+
+	tests := []struct {
+		remote   string
+		expected string
+		wantErr  bool
+	}{
+		{remote: "origin", expected: "refs/pull/12/head"},
+		{remote: "gitlab", expected: "refs/merge-requests/12/head"},
+		{remote: "broken", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.remote, func(t *testing.T) {
+			ref, err := config.PullRequestRef(tt.remote, 12)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PullRequestRef() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if ref != tt.expected {
+				t.Errorf("PullRequestRef() = %v, want %v", ref, tt.expected)
+			}
+		})
+	}
+}
@@ -1,13 +1,14 @@
 package main
 
 import (
-	"bytes"
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 // Global config instance
@@ -15,40 +16,71 @@ var appConfig *Config
 
 // Worktree represents a git worktree
 type Worktree struct {
-	Path   string
-	Branch string
-	Commit string
-	IsMain bool
+	Path           string `json:"path"`
+	Branch         string `json:"branch"`
+	Commit         string `json:"commit"`
+	IsMain         bool   `json:"is_main"`
+	IsBare         bool   `json:"is_bare"`
+	Locked         bool   `json:"locked"`
+	LockReason     string `json:"lock_reason,omitempty"`
+	Prunable       bool   `json:"prunable"`
+	PrunableReason string `json:"prunable_reason,omitempty"`
+	// Status is filled in asynchronously by the TUI, nil until loaded
+	Status *WorktreeStatus `json:"status,omitempty"`
 }
 
 // Branch represents a git branch (local or remote)
 type Branch struct {
 	Name     string
 	IsRemote bool
+	IsTag    bool   // Tags are checked out as detached worktrees
+	Remote   string // Remote of a remote branch, e.g. "origin"
+	// Remotes lists the remotes that have a branch of the same name when
+	// local and remote branches are grouped, see groupBranches
+	Remotes      []string
+	CommitDate   time.Time
+	Author       string
+	Subject      string
+	Upstream     string // e.g. "origin/main", empty if not tracking
+	Ahead        int    // Commits not on the upstream
+	Behind       int    // Upstream commits not on the branch
+	UpstreamGone bool   // The upstream branch was deleted
+	WorktreePath string // Worktree that has the branch checked out, if any
 }
 
-// GetRepoRoot returns the root directory of the git repository
-func GetRepoRoot() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
-	var out bytes.Buffer
-	var stderr bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		errMsg := strings.TrimSpace(stderr.String())
+// Root returns the root directory of the git repository
+func (r *Repository) Root() (string, error) {
+	out, err := r.git("rev-parse", "--show-toplevel")
+	if err != nil {
+		errMsg := err.Error()
 		if strings.Contains(errMsg, "not a git repository") {
 			return "", fmt.Errorf("not a git repository")
 		}
 		return "", fmt.Errorf("git error: %s", errMsg)
 	}
 
-	return strings.TrimSpace(out.String()), nil
+	return strings.TrimSpace(out), nil
+}
+
+// WorktreeDir returns the directory new worktrees are created in
+func (r *Repository) WorktreeDir() (string, error) {
+	repoRoot, err := r.Root()
+	if err != nil {
+		return "", err
+	}
+
+	// Get worktree directory from config, fallback to default
+	worktreeDir := ".worktrees"
+	if appConfig != nil && appConfig.WorktreeDir != "" {
+		worktreeDir = appConfig.WorktreeDir
+	}
+
+	return filepath.Join(repoRoot, worktreeDir), nil
 }
 
 // GenerateWorktreePath generates a path for a worktree based on branch name
-func GenerateWorktreePath(branch string) (string, error) {
-	repoRoot, err := GetRepoRoot()
+func (r *Repository) GenerateWorktreePath(branch string) (string, error) {
+	worktreeDir, err := r.WorktreeDir()
 	if err != nil {
 		return "", err
 	}
@@ -59,41 +91,26 @@ func GenerateWorktreePath(branch string) (string, error) {
 	sanitized = strings.ReplaceAll(sanitized, " ", "-")
 	sanitized = strings.ReplaceAll(sanitized, "\\", "-")
 
-	// Get worktree directory from config, fallback to default
-	worktreeDir := ".worktrees"
-	if appConfig != nil && appConfig.WorktreeDir != "" {
-		worktreeDir = appConfig.WorktreeDir
-	}
-
 	// Create path: <repo-root>/<worktree-dir>/<sanitized-branch-name>
-	worktreePath := filepath.Join(repoRoot, worktreeDir, sanitized)
+	worktreePath := filepath.Join(worktreeDir, sanitized)
 
 	return worktreePath, nil
 }
 
-// ListWorktrees returns all git worktrees in the current repository
-func ListWorktrees() ([]Worktree, error) {
+// ListWorktrees returns all git worktrees in the repository
+func (r *Repository) ListWorktrees() ([]Worktree, error) {
 	// First check if we're in a git repository
-	_, err := GetRepoRoot()
+	_, err := r.Root()
 	if err != nil {
 		return nil, err
 	}
 
-	cmd := exec.Command("git", "worktree", "list", "--porcelain")
-	var out bytes.Buffer
-	var stderr bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		errMsg := strings.TrimSpace(stderr.String())
-		if errMsg != "" {
-			return nil, fmt.Errorf("failed to list worktrees: %s", errMsg)
-		}
-		return nil, fmt.Errorf("failed to list worktrees")
+	out, err := r.git("worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %v", err)
 	}
 
-	worktrees := parseWorktrees(out.String())
+	worktrees := parseWorktrees(out)
 
 	// Even a regular git repo has at least one worktree (the main one)
 	// If we get here with no worktrees, something is wrong
@@ -120,13 +137,8 @@ func parseWorktrees(output string) []Worktree {
 			continue
 		}
 
-		parts := strings.SplitN(line, " ", 2)
-		if len(parts) < 2 {
-			continue
-		}
-
-		key := parts[0]
-		value := parts[1]
+		// Attributes like "bare", "detached" and "locked" may have no value
+		key, value, _ := strings.Cut(line, " ")
 
 		switch key {
 		case "worktree":
@@ -137,9 +149,15 @@ func parseWorktrees(output string) []Worktree {
 			// Remove refs/heads/ prefix
 			current.Branch = strings.TrimPrefix(value, "refs/heads/")
 		case "bare":
-			// Skip bare repositories
+			current.IsBare = true
 		case "detached":
 			current.Branch = "detached"
+		case "locked":
+			current.Locked = true
+			current.LockReason = value
+		case "prunable":
+			current.Prunable = true
+			current.PrunableReason = value
 		}
 	}
 
@@ -156,8 +174,19 @@ func parseWorktrees(output string) []Worktree {
 	return worktrees
 }
 
+// AddOptions controls optional behaviour of AddWorktreeWithOptions
+type AddOptions struct {
+	Base   string // Start point for a new branch (defaults to HEAD)
+	NoCopy bool   // Skip copying configured files into the new worktree
+}
+
 // AddWorktree creates a new worktree
-func AddWorktree(path, branch string, createBranch bool) error {
+func (r *Repository) AddWorktree(path, branch string, createBranch bool) error {
+	return r.AddWorktreeWithOptions(path, branch, createBranch, AddOptions{})
+}
+
+// AddWorktreeWithOptions creates a new worktree, optionally branching from a base ref
+func (r *Repository) AddWorktreeWithOptions(path, branch string, createBranch bool, opts AddOptions) error {
 	// Check if path already exists
 	if _, err := os.Stat(path); err == nil {
 		return fmt.Errorf("directory '%s' already exists. Please remove it first with: rm -rf %s", path, path)
@@ -166,6 +195,10 @@ func AddWorktree(path, branch string, createBranch bool) error {
 	args := []string{"worktree", "add"}
 
 	if createBranch {
+		if opts.Base != "" {
+			// Don't let a feature branch track the base it was started from
+			args = append(args, "--no-track")
+		}
 		args = append(args, "-b", branch)
 	}
 
@@ -173,18 +206,20 @@ func AddWorktree(path, branch string, createBranch bool) error {
 
 	if !createBranch && branch != "" {
 		args = append(args, branch)
+	} else if createBranch && opts.Base != "" {
+		args = append(args, opts.Base)
 	}
 
-	cmd := exec.Command("git", args...)
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
+	if _, err := r.git(args...); err != nil {
+		return fmt.Errorf("failed to add worktree: %v", err)
+	}
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to add worktree: %s", stderr.String())
+	if opts.NoCopy {
+		return nil
 	}
 
 	// Copy configured files to the new worktree
-	if err := CopyConfiguredFiles(path); err != nil {
+	if err := r.CopyConfiguredFiles(path); err != nil {
 		// Log warning but don't fail - worktree was created successfully
 		fmt.Fprintf(os.Stderr, "Warning: failed to copy files: %v\n", err)
 	}
@@ -193,269 +228,1544 @@ func AddWorktree(path, branch string, createBranch bool) error {
 }
 
 // RemoveWorktree removes a worktree
-func RemoveWorktree(path string, force bool) error {
+// force removes worktrees with uncommitted changes and locked worktrees
+func (r *Repository) RemoveWorktree(path string, force bool) error {
 	args := []string{"worktree", "remove"}
 
 	if force {
-		args = append(args, "--force")
+		// git needs --force twice to remove a locked worktree
+		args = append(args, "--force", "--force")
 	}
 
 	args = append(args, path)
 
-	cmd := exec.Command("git", args...)
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to remove worktree: %s", stderr.String())
+	if _, err := r.git(args...); err != nil {
+		return fmt.Errorf("failed to remove worktree: %v", err)
 	}
 
 	return nil
 }
 
-// Title returns the title for the list item
-func (w Worktree) Title() string {
-	if w.IsMain {
-		return fmt.Sprintf("📁 %s (main)", w.Path)
+// RemoveOptions controls optional behaviour of RemoveWorktreeWithOptions
+type RemoveOptions struct {
+	Force bool // Remove worktrees with uncommitted changes and locked worktrees
+	Trash bool // Snapshot the worktree into the trash before removing it
+}
+
+// RemoveWorktreeWithOptions removes a worktree, optionally moving it to the
+// trash first. Returns the trash entry ID, empty when it was not trashed.
+func (r *Repository) RemoveWorktreeWithOptions(path string, opts RemoveOptions) (string, error) {
+	if !opts.Trash {
+		return "", r.RemoveWorktree(path, opts.Force)
+	}
+
+	id, err := r.trashWorktree(path)
+	if err != nil {
+		return "", err
+	}
+	if err := r.RemoveWorktree(path, opts.Force); err != nil {
+		// Put the changes back so nothing is left half-removed
+		if restoreErr := r.restoreSnapshot(path, id); restoreErr != nil {
+			return "", fmt.Errorf("%v; putting its changes back also failed: %v; they are kept in the trash, restore them with 'worktree-util trash restore %s'", err, restoreErr, id)
+		}
+		return "", err
 	}
-	return fmt.Sprintf("📁 %s", w.Path)
+	return id, nil
 }
 
-// Description returns the description for the list item
-func (w Worktree) Description() string {
-	if w.Branch != "" {
-		return fmt.Sprintf("Branch: %s | Commit: %.7s", w.Branch, w.Commit)
+// trashRefPrefix is where trashed worktrees are kept, one ref per removal:
+// refs/worktree-util/trash/<branch>/<timestamp>-<short sha>
+const trashRefPrefix = "refs/worktree-util/trash/"
+
+// trashTime stamps trash entries, replaced in tests
+var trashTime = time.Now
+
+// stashMu serializes stash operations, which all go through refs/stash
+var stashMu sync.Mutex
+
+// trashMessage marks trash commits and precedes the original worktree path
+const trashMessage = "worktree-util trash: "
+
+// TrashEntry is a worktree removed into the trash
+type TrashEntry struct {
+	ID         string    `json:"id"`     // <branch>/<timestamp>-<short sha>, see trashRefPrefix
+	Branch     string    `json:"branch"` // "detached" for detached worktrees
+	Path       string    `json:"path"`   // Where the worktree was
+	Commit     string    `json:"commit"` // Commit the worktree had checked out
+	Date       time.Time `json:"date"`
+	HasChanges bool      `json:"has_changes"` // Uncommitted changes were saved
+}
+
+// trashWorktree records the worktree at path under a new trash ref. Its
+// uncommitted changes, untracked files included, are saved as a stash
+// commit and taken out of the worktree; a clean worktree gets a commit
+// with the tree of HEAD so every entry looks the same.
+func (r *Repository) trashWorktree(path string) (string, error) {
+	branch := "detached"
+	if out, err := r.gitIn(path, "symbolic-ref", "--quiet", "--short", "HEAD"); err == nil {
+		branch = strings.TrimSpace(out)
+	}
+	message := trashMessage + path
+
+	status, err := r.gitIn(path, "status", "--porcelain")
+	if err != nil {
+		return "", fmt.Errorf("failed to get status of %s: %v", path, err)
+	}
+
+	if strings.TrimSpace(status) == "" {
+		out, err := r.gitIn(path, "commit-tree", "HEAD^{tree}", "-p", "HEAD", "-m", "On "+branch+": "+message)
+		if err != nil {
+			return "", fmt.Errorf("failed to snapshot %s: %v", path, err)
+		}
+		return r.writeTrashRef(path, branch, strings.TrimSpace(out))
+	}
+
+	// The stash is shared by all worktrees, stash@{0} must stay ours
+	stashMu.Lock()
+	defer stashMu.Unlock()
+	before, _ := r.git("rev-parse", "--quiet", "--verify", "refs/stash")
+	if _, err := r.gitIn(path, "stash", "push", "--include-untracked", "-m", message); err != nil {
+		return "", fmt.Errorf("failed to save changes in %s: %v", path, err)
+	}
+	out, err := r.git("rev-parse", "--quiet", "--verify", "refs/stash")
+	commit := strings.TrimSpace(out)
+	if err != nil || commit == strings.TrimSpace(before) {
+		return "", fmt.Errorf("failed to save changes in %s: git stash did not record them", path)
+	}
+
+	id, err := r.writeTrashRef(path, branch, commit)
+	if err != nil {
+		// Without the trash ref the stash is the only copy of the changes
+		return "", fmt.Errorf("%v; the changes are kept in the stash list as %q", err, message)
 	}
-	return fmt.Sprintf("Commit: %.7s", w.Commit)
+	// The trash ref keeps the commit, the stash list stays as it was
+	if _, err := r.gitIn(path, "stash", "drop", "--quiet", "stash@{0}"); err != nil {
+		// Not fatal - the changes are in the trash, the stash is a spare copy
+		fmt.Fprintf(os.Stderr, "Warning: failed to drop %q from the stash list: %v\n", message, err)
+	}
+	return id, nil
 }
 
-// FilterValue returns the value to filter on
-func (w Worktree) FilterValue() string {
-	return w.Path + " " + w.Branch
+// writeTrashRef records commit as a new trash entry for the worktree at path
+// and returns its ID. The short SHA keeps IDs from the same second apart.
+func (r *Repository) writeTrashRef(path, branch, commit string) (string, error) {
+	id := fmt.Sprintf("%s/%s-%.7s", branch, trashTime().Format("20060102-150405"), commit)
+	// An empty old value refuses to overwrite an existing entry
+	if _, err := r.git("update-ref", trashRefPrefix+id, commit, ""); err != nil {
+		return "", fmt.Errorf("failed to move %s to the trash: %v", path, err)
+	}
+	return id, nil
 }
 
-// Title returns the title for the branch list item
-func (b Branch) Title() string {
-	if b.IsRemote {
-		return fmt.Sprintf("🌐 %s", b.Name)
+// restoreSnapshot applies the changes saved in trash entry id to the
+// worktree at path and deletes the entry
+func (r *Repository) restoreSnapshot(path, id string) error {
+	ref := trashRefPrefix + id
+	// Stash commits have the index and untracked files as extra parents
+	if _, err := r.git("rev-parse", "--verify", "--quiet", ref+"^2"); err == nil {
+		if _, err := r.gitIn(path, "stash", "apply", "--index", ref); err != nil {
+			return fmt.Errorf("failed to restore changes into %s: %v", path, err)
+		}
 	}
-	return fmt.Sprintf("📌 %s", b.Name)
+	return r.PurgeTrash(id)
 }
 
-// Description returns the description for the branch list item
-func (b Branch) Description() string {
-	return ""
+// ListTrash returns the trashed worktrees, newest first
+func (r *Repository) ListTrash() ([]TrashEntry, error) {
+	out, err := r.git("for-each-ref", "--sort=-creatordate",
+		"--format=%(refname)%00%(creatordate:unix)%00%(parent)%00%(subject)", trashRefPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trash: %v", err)
+	}
+	return parseTrashRefs(out), nil
 }
 
-// FilterValue returns the value to filter on
-func (b Branch) FilterValue() string {
-	return b.Name
+// parseTrashRefs parses the for-each-ref output of ListTrash
+func parseTrashRefs(output string) []TrashEntry {
+	var entries []TrashEntry
+	for _, line := range splitLines(output) {
+		fields := strings.SplitN(line, "\x00", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		id := strings.TrimPrefix(fields[0], trashRefPrefix)
+		slash := strings.LastIndex(id, "/")
+		if slash < 0 {
+			continue
+		}
+		entry := TrashEntry{ID: id, Branch: id[:slash]}
+		if seconds, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+			entry.Date = time.Unix(seconds, 0)
+		}
+		parents := strings.Fields(fields[2])
+		if len(parents) > 0 {
+			entry.Commit = parents[0]
+		}
+		entry.HasChanges = len(parents) > 1
+		if _, path, found := strings.Cut(fields[3], trashMessage); found {
+			entry.Path = path
+		}
+		entries = append(entries, entry)
+	}
+	return entries
 }
 
-// GetLocalBranches returns a list of all local branches
-func GetLocalBranches() ([]string, error) {
-	cmd := exec.Command("git", "branch", "--format=%(refname:short)")
-	var out bytes.Buffer
-	var stderr bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = &stderr
+// FindTrashEntry looks a trash entry up by ID
+func (r *Repository) FindTrashEntry(id string) (TrashEntry, error) {
+	entries, err := r.ListTrash()
+	if err != nil {
+		return TrashEntry{}, err
+	}
+	for _, entry := range entries {
+		if entry.ID == id {
+			return entry, nil
+		}
+	}
+	return TrashEntry{}, fmt.Errorf("no trash entry '%s'", id)
+}
 
-	if err := cmd.Run(); err != nil {
-		errMsg := strings.TrimSpace(stderr.String())
-		return nil, fmt.Errorf("failed to list local branches: %s", errMsg)
+// RestoreTrash recreates a trashed worktree at its original path with its
+// changes and removes the entry from the trash. A branch that was deleted
+// in the meantime is recreated at the trashed commit.
+func (r *Repository) RestoreTrash(entry TrashEntry) error {
+	if entry.Path == "" {
+		return fmt.Errorf("trash entry '%s' does not record a worktree path", entry.ID)
 	}
 
-	output := strings.TrimSpace(out.String())
-	if output == "" {
-		return []string{}, nil
+	_, branchErr := r.git("show-ref", "--verify", "--quiet", "refs/heads/"+entry.Branch)
+
+	var err error
+	switch {
+	case entry.Branch == "detached":
+		err = r.addDetachedWorktree(entry.Path, entry.Commit)
+	case branchErr == nil:
+		err = r.AddWorktree(entry.Path, entry.Branch, false)
+	default:
+		err = r.AddWorktreeWithOptions(entry.Path, entry.Branch, true, AddOptions{Base: entry.Commit})
+	}
+	if err != nil {
+		return err
 	}
 
-	branches := strings.Split(output, "\n")
-	return branches, nil
+	return r.restoreSnapshot(entry.Path, entry.ID)
 }
 
-// GetRemoteBranches returns a list of all remote branches
-func GetRemoteBranches() ([]string, error) {
-	cmd := exec.Command("git", "branch", "-r", "--format=%(refname:short)")
-	var out bytes.Buffer
-	var stderr bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		errMsg := strings.TrimSpace(stderr.String())
-		return nil, fmt.Errorf("failed to list remote branches: %s", errMsg)
+// PurgeTrash deletes trash entries for good
+func (r *Repository) PurgeTrash(ids ...string) error {
+	for _, id := range ids {
+		if _, err := r.git("update-ref", "-d", trashRefPrefix+id); err != nil {
+			return fmt.Errorf("failed to purge '%s' from the trash: %v", id, err)
+		}
 	}
+	return nil
+}
 
-	output := strings.TrimSpace(out.String())
-	if output == "" {
-		return []string{}, nil
+// PrunableWorktrees returns worktrees whose directories are gone and that
+// git worktree prune would remove. Locked worktrees are never pruned.
+func (r *Repository) PrunableWorktrees() ([]Worktree, error) {
+	worktrees, err := r.ListWorktrees()
+	if err != nil {
+		return nil, err
 	}
+	return prunableWorktrees(worktrees), nil
+}
 
-	branches := strings.Split(output, "\n")
-	// Filter out HEAD references
-	var filtered []string
-	for _, branch := range branches {
-		if !strings.Contains(branch, "HEAD") {
-			filtered = append(filtered, branch)
+// prunableWorktrees filters worktrees down to the ones git would prune
+func prunableWorktrees(worktrees []Worktree) []Worktree {
+	var prunable []Worktree
+	for _, wt := range worktrees {
+		if wt.Prunable && !wt.Locked {
+			prunable = append(prunable, wt)
 		}
 	}
-	return filtered, nil
+	return prunable
 }
 
-// GetAllBranches returns a combined list of all local and remote branches
-func GetAllBranches() ([]Branch, error) {
-	var allBranches []Branch
+// PruneWorktrees removes administrative data of stale worktrees
+func (r *Repository) PruneWorktrees() error {
+	if _, err := r.git("worktree", "prune"); err != nil {
+		return fmt.Errorf("failed to prune worktrees: %v", err)
+	}
+	return nil
+}
 
-	// Get local branches
-	localBranches, err := GetLocalBranches()
-	if err != nil {
-		return nil, err
+// LockWorktree locks a worktree so it is not pruned, moved or removed
+func (r *Repository) LockWorktree(path, reason string) error {
+	args := []string{"worktree", "lock"}
+	if reason != "" {
+		args = append(args, "--reason", reason)
 	}
+	args = append(args, path)
 
-	for _, name := range localBranches {
-		allBranches = append(allBranches, Branch{
-			Name:     name,
-			IsRemote: false,
-		})
+	if _, err := r.git(args...); err != nil {
+		return fmt.Errorf("failed to lock worktree: %v", err)
 	}
+	return nil
+}
 
-	// Get remote branches
-	remoteBranches, err := GetRemoteBranches()
-	if err != nil {
-		return nil, err
+// UnlockWorktree unlocks a locked worktree
+func (r *Repository) UnlockWorktree(path string) error {
+	if _, err := r.git("worktree", "unlock", path); err != nil {
+		return fmt.Errorf("failed to unlock worktree: %v", err)
 	}
+	return nil
+}
 
-	for _, name := range remoteBranches {
-		allBranches = append(allBranches, Branch{
-			Name:     name,
-			IsRemote: true,
-		})
+// MoveWorktree moves a worktree to a new path; force also moves locked worktrees
+func (r *Repository) MoveWorktree(from, to string, force bool) error {
+	args := []string{"worktree", "move"}
+	if force {
+		// git needs --force twice to move a locked worktree
+		args = append(args, "--force", "--force")
 	}
+	args = append(args, from, to)
 
-	return allBranches, nil
+	if _, err := r.git(args...); err != nil {
+		return fmt.Errorf("failed to move worktree: %v", err)
+	}
+	return nil
 }
 
-// CreateWorktreeFromBranch creates a new worktree from an existing local or remote branch
-// branchName can be a local branch name (e.g., "feature") or a remote branch (e.g., "origin/feature")
-// Returns the path where the worktree was created
-func CreateWorktreeFromBranch(branchName string) (string, error) {
-	branchName = strings.TrimSpace(branchName)
-	if branchName == "" {
-		return "", fmt.Errorf("branch name cannot be empty")
+// RenameWorktree renames the branch of a worktree to newBranch (when it
+// differs) and moves the worktree to newPath. An empty newPath is generated
+// from the branch name with the configured worktree_dir. Returns the new path.
+func (r *Repository) RenameWorktree(wt Worktree, newBranch, newPath string, force bool) (string, error) {
+	if wt.IsMain {
+		return "", fmt.Errorf("cannot move main worktree")
+	}
+	if wt.Locked && !force {
+		return "", lockedError(wt)
 	}
 
-	// Get local and remote branches
-	localBranches, err := GetLocalBranches()
-	if err != nil {
-		return "", err
+	detached := wt.Branch == "" || wt.Branch == "detached"
+	newBranch = strings.TrimSpace(newBranch)
+	if newBranch == "" && !detached {
+		newBranch = wt.Branch
+	}
+	if detached && newBranch != "" {
+		return "", fmt.Errorf("cannot rename the branch of a detached worktree")
 	}
 
-	remoteBranches, err := GetRemoteBranches()
-	if err != nil {
-		return "", err
+	if newPath == "" {
+		if detached {
+			return "", fmt.Errorf("detached worktree has no branch name; specify a new path")
+		}
+		generated, err := r.GenerateWorktreePath(newBranch)
+		if err != nil {
+			return "", err
+		}
+		newPath = generated
 	}
 
-	// Check if branch exists locally
-	isLocal := false
-	for _, branch := range localBranches {
-		if branch == branchName {
-			isLocal = true
-			break
+	moved := filepath.Clean(newPath) != filepath.Clean(wt.Path)
+	if moved {
+		// git would move the worktree inside an existing directory
+		if _, err := os.Stat(newPath); err == nil {
+			return "", fmt.Errorf("directory '%s' already exists", newPath)
 		}
 	}
 
-	// Check if branch exists remotely
-	isRemote := false
-	remoteBranchName := ""
-	localBranchName := branchName
+	renamed := newBranch != wt.Branch && !detached
+	if renamed {
+		if _, err := r.gitIn(wt.Path, "branch", "-m", wt.Branch, newBranch); err != nil {
+			return "", fmt.Errorf("failed to rename branch: %v", err)
+		}
+	}
 
-	// If branchName contains a slash, it might be a remote branch reference
-	if strings.Contains(branchName, "/") {
-		for _, branch := range remoteBranches {
-			if branch == branchName {
-				isRemote = true
-				remoteBranchName = branchName
-				// Extract local branch name (e.g., "origin/feature" -> "feature")
-				parts := strings.SplitN(branchName, "/", 2)
-				if len(parts) == 2 {
-					localBranchName = parts[1]
+	if moved {
+		if err := r.MoveWorktree(wt.Path, newPath, force); err != nil {
+			if renamed {
+				// Put the branch name back so the worktree is left as it was
+				if _, rollbackErr := r.gitIn(wt.Path, "branch", "-m", newBranch, wt.Branch); rollbackErr != nil {
+					return "", fmt.Errorf("%v; renaming the branch back to %s also failed, it is still called %s: %v", err, wt.Branch, newBranch, rollbackErr)
 				}
-				break
 			}
+			return "", err
 		}
-	} else {
-		// Check if there's a remote branch with this name
-		for _, branch := range remoteBranches {
-			// Check for origin/<branchName> or any other remote
-			if strings.HasSuffix(branch, "/"+branchName) {
-				isRemote = true
-				remoteBranchName = branch
-				break
+	}
+
+	return newPath, nil
+}
+
+// BrokenWorktree is a linked worktree whose .git file no longer points at
+// this repository, typically because the main repository was moved
+type BrokenWorktree struct {
+	Path   string // Worktree directory
+	GitDir string // Where its .git file points
+	Reason string
+}
+
+// FindBrokenWorktrees looks for linked worktrees whose .git file points to a
+// missing or foreign gitdir. It checks the listed worktrees and every
+// directory in the configured worktree_dir, which is where worktrees end up
+// after the main repository (and its .worktrees folder) was moved.
+func (r *Repository) FindBrokenWorktrees() ([]BrokenWorktree, error) {
+	out, err := r.git("rev-parse", "--path-format=absolute", "--git-common-dir")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find git directory: %v", err)
+	}
+	adminDir := filepath.Join(strings.TrimSpace(out), "worktrees")
+
+	worktrees, err := r.ListWorktrees()
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []string
+	for _, wt := range worktrees {
+		if !wt.IsMain {
+			candidates = append(candidates, wt.Path)
+		}
+	}
+
+	// Worktrees moved together with the repository are no longer listed
+	// under their new path, so look for them in worktree_dir as well
+	if path, err := r.WorktreeDir(); err == nil {
+		if entries, err := os.ReadDir(path); err == nil {
+			for _, entry := range entries {
+				if entry.IsDir() {
+					candidates = append(candidates, filepath.Join(path, entry.Name()))
+				}
 			}
 		}
 	}
 
-	if !isLocal && !isRemote {
-		return "", fmt.Errorf("branch '%s' not found in local or remote branches", branchName)
+	var broken []BrokenWorktree
+	seen := map[string]bool{}
+	for _, path := range candidates {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		gitDir, err := readGitFile(path)
+		if err != nil {
+			// Missing directories are prunable, not repairable; plain
+			// directories without a .git file are not worktrees
+			continue
+		}
+
+		switch {
+		case !pathExists(gitDir):
+			broken = append(broken, BrokenWorktree{Path: path, GitDir: gitDir, Reason: "gitdir points to a non-existent location"})
+		case !isWithinDir(gitDir, adminDir):
+			broken = append(broken, BrokenWorktree{Path: path, GitDir: gitDir, Reason: "gitdir points to another repository"})
+		}
 	}
 
-	// Check if a worktree already exists for this branch
-	existingWorktrees, err := ListWorktrees()
+	return broken, nil
+}
+
+// RepairWorktrees runs git worktree repair for the given worktree paths
+func (r *Repository) RepairWorktrees(paths []string) error {
+	args := append([]string{"worktree", "repair"}, paths...)
+	if _, err := r.git(args...); err != nil {
+		return fmt.Errorf("failed to repair worktrees: %v", err)
+	}
+	return nil
+}
+
+// readGitFile returns the gitdir a linked worktree's .git file points to
+func readGitFile(worktreePath string) (string, error) {
+	gitFile := filepath.Join(worktreePath, ".git")
+	info, err := os.Stat(gitFile)
 	if err != nil {
 		return "", err
 	}
-
-	for _, wt := range existingWorktrees {
-		// Check if this worktree is for the branch we want
-		if wt.Branch == localBranchName || wt.Branch == branchName {
-			// Worktree already exists, return its path
-			return wt.Path, nil
-		}
+	if info.IsDir() {
+		return "", fmt.Errorf("%s is a repository, not a linked worktree", worktreePath)
 	}
 
-	// Generate path for the worktree
-	// Use the local branch name for path generation
-	path, err := GenerateWorktreePath(localBranchName)
+	data, err := os.ReadFile(gitFile)
 	if err != nil {
 		return "", err
 	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+	if !ok {
+		return "", fmt.Errorf("%s is not a valid .git file", gitFile)
+	}
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(worktreePath, gitDir)
+	}
+	return filepath.Clean(gitDir), nil
+}
 
-	// Create the worktree
-	var cmd *exec.Cmd
-	if isLocal {
-		// Use existing local branch
-		cmd = exec.Command("git", "worktree", "add", path, branchName)
-	} else {
-		// Create local tracking branch from remote
-		// git worktree add <path> -b <local-name> --track <remote-branch>
-		cmd = exec.Command("git", "worktree", "add", path, "-b", localBranchName, "--track", remoteBranchName)
+// pathExists reports whether path exists
+func pathExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// WorktreeChanges describes work in a worktree that would be lost on removal
+type WorktreeChanges struct {
+	Uncommitted []string // git status --porcelain lines
+	Unpushed    []string // commits on no remote and no other local branch, one line each
+}
+
+// HasChanges reports whether the worktree has uncommitted or unpushed work
+func (c WorktreeChanges) HasChanges() bool {
+	return len(c.Uncommitted) > 0 || len(c.Unpushed) > 0
+}
+
+// GetWorktreeChanges reports uncommitted changes and commits that would only
+// be left on the worktree's branch: not on any remote and not on any other
+// local branch, so a branch merged locally or a repository without remotes
+// does not count its whole history
+func (r *Repository) GetWorktreeChanges(path string) (WorktreeChanges, error) {
+	var changes WorktreeChanges
+
+	out, err := r.gitIn(path, "status", "--porcelain")
+	if err != nil {
+		return changes, fmt.Errorf("failed to get status of %s: %v", path, err)
+	}
+	changes.Uncommitted = splitLines(out)
+
+	args := []string{"log", "--oneline", "HEAD", "--not"}
+	if out, err := r.gitIn(path, "symbolic-ref", "--quiet", "--short", "HEAD"); err == nil {
+		// --exclude only applies to the --branches that follows it
+		args = append(args, "--exclude="+strings.TrimSpace(out))
+	}
+	args = append(args, "--branches", "--remotes")
+	out, err = r.gitIn(path, args...)
+	if err != nil {
+		return changes, fmt.Errorf("failed to list unpushed commits of %s: %v", path, err)
 	}
+	changes.Unpushed = splitLines(out)
+
+	return changes, nil
+}
+
+// WorktreeStatus summarizes git status --porcelain=v2 --branch for a worktree
+type WorktreeStatus struct {
+	Changed   int    `json:"changed"`   // Tracked files with staged or unstaged changes
+	Untracked int    `json:"untracked"` // Untracked files
+	Conflicts int    `json:"conflicts"` // Unmerged files
+	Upstream  string `json:"upstream,omitempty"`
+	Ahead     int    `json:"ahead"`   // Commits not pushed to the upstream
+	Behind    int    `json:"behind"`  // Upstream commits not pulled yet
+	Stashes   int    `json:"stashes"` // Stash entries made on the worktree's branch
+}
 
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
+// IsDirty reports whether the worktree has uncommitted or untracked files
+func (s WorktreeStatus) IsDirty() bool {
+	return s.Changed > 0 || s.Untracked > 0 || s.Conflicts > 0
+}
 
-	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("failed to create worktree: %s", stderr.String())
+// Indicators renders the status compactly, e.g. "● 2 changed ? 1 untracked ↑1"
+func (s WorktreeStatus) Indicators() string {
+	var parts []string
+	if s.Conflicts > 0 {
+		parts = append(parts, fmt.Sprintf("✗ %d conflicts", s.Conflicts))
+	}
+	if s.Changed > 0 {
+		parts = append(parts, fmt.Sprintf("● %d changed", s.Changed))
+	}
+	if s.Untracked > 0 {
+		parts = append(parts, fmt.Sprintf("? %d untracked", s.Untracked))
 	}
+	if s.Ahead > 0 {
+		parts = append(parts, fmt.Sprintf("↑%d", s.Ahead))
+	}
+	if s.Behind > 0 {
+		parts = append(parts, fmt.Sprintf("↓%d", s.Behind))
+	}
+	if s.Stashes > 0 {
+		parts = append(parts, fmt.Sprintf("≡ %d stashed", s.Stashes))
+	}
+	if len(parts) == 0 {
+		return "✓ clean"
+	}
+	return strings.Join(parts, " ")
+}
 
-	// Copy configured files to the new worktree
-	if err := CopyConfiguredFiles(path); err != nil {
-		// Log warning but don't fail - worktree was created successfully
-		fmt.Fprintf(os.Stderr, "Warning: failed to copy files: %v\n", err)
+// statusWorkers bounds the number of concurrent git status processes
+const statusWorkers = 8
+
+// GetWorktreeStatus runs git status --porcelain=v2 --branch in a worktree
+func (r *Repository) GetWorktreeStatus(path string) (WorktreeStatus, error) {
+	out, err := r.gitIn(path, "status", "--porcelain=v2", "--branch")
+	if err != nil {
+		return WorktreeStatus{}, fmt.Errorf("failed to get status of %s: %v", path, err)
+	}
+	return parseStatusV2(out), nil
+}
+
+// GetWorktreeStatuses gets the status of all worktrees concurrently, keyed
+// by path. Bare and prunable worktrees, and worktrees whose status cannot
+// be read, are left out
+func (r *Repository) GetWorktreeStatuses(worktrees []Worktree) map[string]WorktreeStatus {
+	// Stashes are shared by all worktrees, count them per branch once
+	var stashes map[string]int
+	if out, err := r.git("stash", "list", "--format=%gs"); err == nil {
+		stashes = countStashesByBranch(out)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	statuses := make(map[string]WorktreeStatus)
+	jobs := make(chan Worktree)
+
+	for i := 0; i < statusWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for wt := range jobs {
+				status, err := r.GetWorktreeStatus(wt.Path)
+				if err != nil {
+					continue
+				}
+				status.Stashes = stashes[wt.Branch]
+
+				mu.Lock()
+				statuses[wt.Path] = status
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, wt := range worktrees {
+		if wt.IsBare || wt.Prunable {
+			continue
+		}
+		jobs <- wt
 	}
+	close(jobs)
+	wg.Wait()
+
+	return statuses
+}
 
-	return path, nil
+// parseStatusV2 parses the output of git status --porcelain=v2 --branch
+func parseStatusV2(output string) WorktreeStatus {
+	var status WorktreeStatus
+	for _, line := range splitLines(output) {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "#":
+			header, fields, _ := strings.Cut(value, " ")
+			switch header {
+			case "branch.upstream":
+				status.Upstream = fields
+			case "branch.ab":
+				// "+<ahead> -<behind>"
+				ahead, behind, _ := strings.Cut(fields, " ")
+				status.Ahead, _ = strconv.Atoi(strings.TrimPrefix(ahead, "+"))
+				status.Behind, _ = strconv.Atoi(strings.TrimPrefix(behind, "-"))
+			}
+		case "1", "2":
+			status.Changed++
+		case "u":
+			status.Conflicts++
+		case "?":
+			status.Untracked++
+		}
+	}
+	return status
+}
+
+// countStashesByBranch counts git stash list --format=%gs entries, which
+// look like "WIP on <branch>: ..." or "On <branch>: ...", per branch
+func countStashesByBranch(output string) map[string]int {
+	counts := make(map[string]int)
+	for _, line := range splitLines(output) {
+		if branch, ok := stashBranch(line); ok {
+			counts[branch]++
+		}
+	}
+	return counts
+}
+
+// stashBranch returns the branch a stash subject such as "WIP on main: ..."
+// was made on
+func stashBranch(subject string) (string, bool) {
+	subject = strings.TrimPrefix(subject, "WIP ")
+	rest, ok := strings.CutPrefix(subject, "On ")
+	if !ok {
+		rest, ok = strings.CutPrefix(subject, "on ")
+	}
+	if !ok {
+		return "", false
+	}
+	branch, _, found := strings.Cut(rest, ":")
+	return branch, found
+}
+
+// BranchStashes lists the stash entries made on branch, e.g.
+// "stash@{0}: On feature: wip"
+func (r *Repository) BranchStashes(branch string) ([]string, error) {
+	out, err := r.git("stash", "list", "--format=%gd%x00%gs")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stashes: %v", err)
+	}
+
+	var stashes []string
+	for _, line := range splitLines(out) {
+		ref, subject, _ := strings.Cut(line, "\x00")
+		if b, ok := stashBranch(subject); ok && b == branch {
+			stashes = append(stashes, ref+": "+subject)
+		}
+	}
+	return stashes, nil
+}
+
+// StashWorktree stashes the uncommitted changes of a worktree, untracked
+// files included. The stash is shared by all worktrees, so it outlives the
+// worktree; returns the stash ref, e.g. "stash@{0}".
+func (r *Repository) StashWorktree(path string) (string, error) {
+	message := fmt.Sprintf("worktree-util: before removing %s", path)
+	stashMu.Lock()
+	defer stashMu.Unlock()
+	if _, err := r.gitIn(path, "stash", "push", "--include-untracked", "-m", message); err != nil {
+		return "", fmt.Errorf("failed to stash changes in %s: %v", path, err)
+	}
+	return "stash@{0}", nil
+}
+
+// GetWorktreePreview describes a worktree for the preview pane: recent
+// commits, git status and a diffstat against its upstream, or the default
+// branch when it has none
+func (r *Repository) GetWorktreePreview(wt Worktree) (string, error) {
+	if wt.IsBare {
+		return "Bare repository, nothing checked out", nil
+	}
+	if wt.Prunable {
+		return "Worktree directory is missing: " + wt.PrunableReason, nil
+	}
+
+	var b strings.Builder
+
+	out, err := r.gitIn(wt.Path, "log", "--oneline", "-n", "10")
+	if err != nil {
+		return "", fmt.Errorf("failed to read log of %s: %v", wt.Path, err)
+	}
+	b.WriteString("Recent commits\n")
+	b.WriteString(strings.TrimRight(out, "\n") + "\n")
+
+	out, err = r.gitIn(wt.Path, "status", "--short")
+	if err != nil {
+		return "", fmt.Errorf("failed to get status of %s: %v", wt.Path, err)
+	}
+	b.WriteString("\nStatus\n")
+	if strings.TrimSpace(out) == "" {
+		b.WriteString("clean\n")
+	} else {
+		b.WriteString(strings.TrimRight(out, "\n") + "\n")
+	}
+
+	base, err := r.gitIn(wt.Path, "rev-parse", "--abbrev-ref", "@{upstream}")
+	if err != nil {
+		base, err = r.DefaultBranch()
+	}
+	base = strings.TrimSpace(base)
+	if err != nil || base == "" {
+		b.WriteString("\nNo upstream or default branch to compare with\n")
+		return b.String(), nil
+	}
+
+	out, err = r.gitIn(wt.Path, "diff", "--stat", base+"...HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to diff %s against %s: %v", wt.Path, base, err)
+	}
+	b.WriteString("\nChanges against " + base + "\n")
+	if strings.TrimSpace(out) == "" {
+		b.WriteString("none\n")
+	} else {
+		b.WriteString(strings.TrimRight(out, "\n") + "\n")
+	}
+
+	return b.String(), nil
+}
+
+// DeleteBranch deletes a local branch; force uses -D to delete unmerged branches
+func (r *Repository) DeleteBranch(branch string, force bool) error {
+	flag := "-d"
+	if force {
+		flag = "-D"
+	}
+
+	if _, err := r.git("branch", flag, branch); err != nil {
+		return fmt.Errorf("failed to delete branch: %v", err)
+	}
+
+	return nil
+}
+
+// BranchMergeState tells whether a branch can be deleted without losing work
+type BranchMergeState struct {
+	Merged bool
+	Target string // The upstream of the branch, or the branch at HEAD without one
+	// MergedIntoDefault tells whether the branch is merged into Default, the
+	// default branch (or the branch at HEAD without one). Being merged into
+	// its own upstream does not make deleting that upstream safe.
+	MergedIntoDefault bool
+	Default           string
+}
+
+// BranchMerged checks branch the way git branch -d does: against its
+// upstream, or against HEAD when it has none. It also checks it against the
+// default branch, which decides whether the remote branch may go.
+func (r *Repository) BranchMerged(branch string) (BranchMergeState, error) {
+	head := "HEAD"
+	if out, err := r.git("rev-parse", "--abbrev-ref", "HEAD"); err == nil {
+		head = strings.TrimSpace(out)
+	}
+
+	state := BranchMergeState{Target: head, Default: head}
+	target := "HEAD"
+	if out, err := r.git("rev-parse", "--abbrev-ref", branch+"@{upstream}"); err == nil {
+		target = strings.TrimSpace(out)
+		state.Target = target
+	}
+	defaultTarget := "HEAD"
+	if defaultBranch, err := r.DefaultBranch(); err == nil {
+		defaultTarget = defaultBranch
+		state.Default = defaultBranch
+	}
+
+	var err error
+	if state.Merged, err = r.branchMergedInto(branch, target); err != nil {
+		return state, err
+	}
+	if state.MergedIntoDefault, err = r.branchMergedInto(branch, defaultTarget); err != nil {
+		return state, err
+	}
+	return state, nil
+}
+
+// branchMergedInto reports whether branch is reachable from target
+func (r *Repository) branchMergedInto(branch, target string) (bool, error) {
+	out, err := r.git("branch", "--list", "--merged", target, "--format=%(refname:short)", branch)
+	if err != nil {
+		return false, fmt.Errorf("failed to check whether %s is merged: %v", branch, err)
+	}
+	return strings.TrimSpace(out) == branch, nil
+}
+
+// BranchUpstream returns the remote and the branch on it that a local
+// branch tracks, both empty when it does not track a remote branch
+func (r *Repository) BranchUpstream(branch string) (string, string, error) {
+	out, err := r.git("for-each-ref", "--format=%(upstream:remotename)%00%(upstream:remoteref)", "refs/heads/"+branch)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get upstream of %s: %v", branch, err)
+	}
+
+	remote, ref, _ := strings.Cut(strings.TrimSpace(out), "\x00")
+	name, ok := strings.CutPrefix(ref, "refs/heads/")
+	// Local upstreams ("remote" .) and pull request refs cannot be deleted
+	if remote == "" || remote == "." || !ok {
+		return "", "", nil
+	}
+	return remote, name, nil
+}
+
+// DeleteRemoteBranch deletes branch on remote
+func (r *Repository) DeleteRemoteBranch(remote, branch string) error {
+	if _, err := r.git("push", remote, "--delete", branch); err != nil {
+		return fmt.Errorf("failed to delete remote branch: %v", err)
+	}
+
+	return nil
+}
+
+// lockedError explains why a locked worktree cannot be removed
+func lockedError(wt Worktree) error {
+	if wt.LockReason != "" {
+		return fmt.Errorf("worktree is locked: %s", wt.LockReason)
+	}
+	return fmt.Errorf("worktree is locked")
+}
+
+// splitLines splits command output into non-empty lines
+func splitLines(output string) []string {
+	output = strings.TrimRight(output, "\n")
+	if output == "" {
+		return nil
+	}
+	return strings.Split(output, "\n")
+}
+
+// States returns the special states of the worktree (bare, locked, prunable)
+func (w Worktree) States() []string {
+	var states []string
+	if w.IsBare {
+		states = append(states, "bare")
+	}
+	if w.Locked {
+		states = append(states, "locked")
+	}
+	if w.Prunable {
+		states = append(states, "prunable")
+	}
+	return states
+}
+
+// Title returns the title for the list item
+func (w Worktree) Title() string {
+	title := fmt.Sprintf("📁 %s", w.Path)
+	if w.IsMain {
+		title += " (main)"
+	}
+	if w.IsBare {
+		title += " [bare]"
+	}
+	if w.Locked {
+		title += " 🔒 locked"
+	}
+	if w.Prunable {
+		title += " ⚠ prunable"
+	}
+	return title
+}
+
+// Description returns the description for the list item
+func (w Worktree) Description() string {
+	var desc string
+	if w.IsBare {
+		desc = "Bare repository"
+	} else if w.Branch != "" {
+		desc = fmt.Sprintf("Branch: %s | Commit: %.7s", w.Branch, w.Commit)
+	} else {
+		desc = fmt.Sprintf("Commit: %.7s", w.Commit)
+	}
+	if w.LockReason != "" {
+		desc += " | Locked: " + w.LockReason
+	}
+	if w.PrunableReason != "" {
+		desc += " | Prunable: " + w.PrunableReason
+	}
+	if w.Status != nil {
+		desc += " | " + w.Status.Indicators()
+	}
+	return desc
+}
+
+// FilterValue returns the value to filter on
+func (w Worktree) FilterValue() string {
+	return w.Path + " " + w.Branch
+}
+
+// Title returns the title for the branch list item
+func (b Branch) Title() string {
+	if b.IsTag {
+		return fmt.Sprintf("🏷️ %s", b.Name)
+	}
+	title := fmt.Sprintf("📌 %s", b.Name)
+	if b.IsRemote {
+		title = fmt.Sprintf("🌐 %s", b.Name)
+	}
+	if len(b.Remotes) > 0 {
+		title += " (" + strings.Join(b.Remotes, ", ") + ")"
+	}
+	if b.WorktreePath != "" {
+		title += " [worktree]"
+	}
+	return title
+}
+
+// Description returns the description for the branch list item:
+// last commit date, author, tracking state and subject
+func (b Branch) Description() string {
+	var parts []string
+	if !b.CommitDate.IsZero() {
+		parts = append(parts, relativeTime(b.CommitDate, time.Now()))
+	}
+	if b.Author != "" {
+		parts = append(parts, b.Author)
+	}
+	if b.Upstream != "" {
+		tracking := "→ " + b.Upstream
+		if b.UpstreamGone {
+			tracking += " (gone)"
+		}
+		if b.Ahead > 0 {
+			tracking += fmt.Sprintf(" ↑%d", b.Ahead)
+		}
+		if b.Behind > 0 {
+			tracking += fmt.Sprintf(" ↓%d", b.Behind)
+		}
+		parts = append(parts, tracking)
+	}
+	if b.Subject != "" {
+		parts = append(parts, b.Subject)
+	}
+	return strings.Join(parts, " • ")
+}
+
+// relativeTime formats t relative to now, e.g. "3 days ago"
+func relativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	unit := func(n int, name string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s ago", name)
+		}
+		return fmt.Sprintf("%d %ss ago", n, name)
+	}
+
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return unit(int(d/time.Minute), "minute")
+	case d < 24*time.Hour:
+		return unit(int(d/time.Hour), "hour")
+	case d < 30*24*time.Hour:
+		return unit(int(d/(24*time.Hour)), "day")
+	case d < 365*24*time.Hour:
+		return unit(int(d/(30*24*time.Hour)), "month")
+	default:
+		return unit(int(d/(365*24*time.Hour)), "year")
+	}
+}
+
+// FilterValue returns the value to filter on
+func (b Branch) FilterValue() string {
+	return b.Name
+}
+
+// GetLocalBranches returns a list of all local branches
+func (r *Repository) GetLocalBranches() ([]string, error) {
+	out, err := r.git("branch", "--format=%(refname:short)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list local branches: %v", err)
+	}
+
+	output := strings.TrimSpace(out)
+	if output == "" {
+		return []string{}, nil
+	}
+
+	branches := strings.Split(output, "\n")
+	return branches, nil
+}
+
+// GetRemoteBranches returns a list of all remote branches
+func (r *Repository) GetRemoteBranches() ([]string, error) {
+	out, err := r.git("branch", "-r", "--format=%(refname:short)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote branches: %v", err)
+	}
+
+	output := strings.TrimSpace(out)
+	if output == "" {
+		return []string{}, nil
+	}
+
+	branches := strings.Split(output, "\n")
+	// Filter out HEAD references
+	var filtered []string
+	for _, branch := range branches {
+		if !strings.Contains(branch, "HEAD") {
+			filtered = append(filtered, branch)
+		}
+	}
+	return filtered, nil
+}
+
+// branchRefFormat is the for-each-ref format parsed by parseBranchRefs,
+// one NUL-separated record per line
+const branchRefFormat = "%(refname)%00%(committerdate:unix)%00%(authorname)%00%(upstream:short)%00%(upstream:track,nobracket)%00%(subject)%00%(worktreepath)"
+
+// GetAllBranches returns all local and remote branches with their last
+// commit, most recently committed first
+func (r *Repository) GetAllBranches() ([]Branch, error) {
+	out, err := r.git("for-each-ref", "--sort=-committerdate", "--format="+branchRefFormat, "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %v", err)
+	}
+	remotes, err := r.GetRemotes()
+	if err != nil {
+		return nil, err
+	}
+	return parseBranchRefs(out, remotes), nil
+}
+
+// parseBranchRefs parses the output of for-each-ref with branchRefFormat
+func parseBranchRefs(output string, remotes []string) []Branch {
+	var branches []Branch
+	for _, line := range splitLines(output) {
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 7 {
+			continue
+		}
+
+		var branch Branch
+		if name, ok := strings.CutPrefix(fields[0], "refs/heads/"); ok {
+			branch.Name = name
+		} else if name, ok := strings.CutPrefix(fields[0], "refs/remotes/"); ok {
+			// Skip symbolic refs like origin/HEAD
+			if strings.HasSuffix(name, "/HEAD") {
+				continue
+			}
+			branch.Name = name
+			branch.IsRemote = true
+			for _, remote := range remotes {
+				if strings.HasPrefix(name, remote+"/") {
+					branch.Remote = remote
+					break
+				}
+			}
+		} else {
+			continue
+		}
+
+		if seconds, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+			branch.CommitDate = time.Unix(seconds, 0)
+		}
+		branch.Author = fields[2]
+		branch.Upstream = fields[3]
+		branch.Ahead, branch.Behind, branch.UpstreamGone = parseTrack(fields[4])
+		branch.Subject = fields[5]
+		branch.WorktreePath = fields[6]
+
+		branches = append(branches, branch)
+	}
+	return branches
+}
+
+// groupBranches collapses a local branch and its namesakes on remotes into
+// one entry that lists the remotes. Branches only on remotes become one
+// entry named without the remote. Tags and order are kept
+func groupBranches(branches []Branch) []Branch {
+	var grouped []Branch
+	index := make(map[string]int)
+
+	for _, branch := range branches {
+		if branch.IsTag {
+			grouped = append(grouped, branch)
+			continue
+		}
+
+		name := branch.Name
+		if branch.IsRemote && branch.Remote != "" {
+			name = strings.TrimPrefix(branch.Name, branch.Remote+"/")
+		}
+
+		i, seen := index[name]
+		switch {
+		case !seen:
+			if branch.IsRemote {
+				branch.Name = name
+				branch.Remotes = []string{branch.Remote}
+			}
+			index[name] = len(grouped)
+			grouped = append(grouped, branch)
+		case branch.IsRemote:
+			grouped[i].Remotes = append(grouped[i].Remotes, branch.Remote)
+		default:
+			// The local branch wins over remote copies seen first
+			branch.Remotes = grouped[i].Remotes
+			grouped[i] = branch
+		}
+	}
+	return grouped
+}
+
+// parseTrack parses %(upstream:track,nobracket), e.g. "ahead 1, behind 2" or "gone"
+func parseTrack(track string) (ahead, behind int, gone bool) {
+	if track == "gone" {
+		return 0, 0, true
+	}
+	for _, part := range strings.Split(track, ", ") {
+		key, value, _ := strings.Cut(part, " ")
+		count, _ := strconv.Atoi(value)
+		switch key {
+		case "ahead":
+			ahead = count
+		case "behind":
+			behind = count
+		}
+	}
+	return ahead, behind, false
+}
+
+// GetCheckoutRefs returns all branches followed by all tags, as listed in
+// the checkout picker
+func (r *Repository) GetCheckoutRefs() ([]Branch, error) {
+	refs, err := r.GetAllBranches()
+	if err != nil {
+		return nil, err
+	}
+	tags, err := r.GetTags()
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		refs = append(refs, Branch{Name: tag, IsTag: true})
+	}
+	return refs, nil
+}
+
+// DefaultBranch returns the branch origin/HEAD points to, e.g. "origin/main"
+func (r *Repository) DefaultBranch() (string, error) {
+	out, err := r.git("symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to detect default branch: %v", err)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// GetTags returns all tags, newest first
+func (r *Repository) GetTags() ([]string, error) {
+	out, err := r.git("tag", "--list", "--sort=-creatordate")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %v", err)
+	}
+	return splitLines(out), nil
+}
+
+// BaseRefs returns the branches and tags a new branch can start from
+func (r *Repository) BaseRefs() ([]string, error) {
+	branches, err := r.GetAllBranches()
+	if err != nil {
+		return nil, err
+	}
+	tags, err := r.GetTags()
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]string, 0, len(branches)+len(tags))
+	for _, branch := range branches {
+		refs = append(refs, branch.Name)
+	}
+	return append(refs, tags...), nil
+}
+
+// CreateWorktreeFromBranch creates a new worktree from an existing local or remote branch
+// branchName can be a local branch name (e.g., "feature") or a remote branch (e.g., "origin/feature")
+// Returns the path where the worktree was created
+func (r *Repository) CreateWorktreeFromBranch(branchName string) (string, error) {
+	path, _, err := r.CheckoutBranchWorktree(branchName)
+	return path, err
+}
+
+// CheckoutBranchWorktree works like CreateWorktreeFromBranch but also reports
+// whether a new worktree was created (true) or an existing one was reused (false)
+// Names that are not branches are checked out with CheckoutDetachedWorktree
+func (r *Repository) CheckoutBranchWorktree(branchName string) (string, bool, error) {
+	branchName = strings.TrimSpace(branchName)
+	if branchName == "" {
+		return "", false, fmt.Errorf("branch name cannot be empty")
+	}
+
+	// Get local and remote branches
+	localBranches, err := r.GetLocalBranches()
+	if err != nil {
+		return "", false, err
+	}
+
+	remoteBranches, err := r.GetRemoteBranches()
+	if err != nil {
+		return "", false, err
+	}
+
+	// Check if branch exists locally
+	isLocal := false
+	for _, branch := range localBranches {
+		if branch == branchName {
+			isLocal = true
+			break
+		}
+	}
+
+	// Check if branch exists remotely
+	isRemote := false
+	remoteBranchName := ""
+	localBranchName := branchName
+
+	if !isLocal {
+		remotes, err := r.GetRemotes()
+		if err != nil {
+			return "", false, err
+		}
+
+		remoteBranchName, localBranchName, err = resolveRemoteBranch(branchName, remotes, remoteBranches)
+		if err != nil {
+			return "", false, err
+		}
+		isRemote = remoteBranchName != ""
+		if !isRemote {
+			localBranchName = branchName
+		}
+	}
+
+	// Anything else may still be a tag or commit to check out detached
+	if !isLocal && !isRemote {
+		return r.CheckoutDetachedWorktree(branchName)
+	}
+
+	// Check if a worktree already exists for this branch
+	existingWorktrees, err := r.ListWorktrees()
+	if err != nil {
+		return "", false, err
+	}
+
+	for _, wt := range existingWorktrees {
+		// Check if this worktree is for the branch we want
+		if wt.Branch == localBranchName || wt.Branch == branchName {
+			// Worktree already exists, return its path
+			return wt.Path, false, nil
+		}
+	}
+
+	// Generate path for the worktree
+	// Use the local branch name for path generation
+	path, err := r.GenerateWorktreePath(localBranchName)
+	if err != nil {
+		return "", false, err
+	}
+
+	// Create the worktree
+	var args []string
+	if isLocal {
+		// Use existing local branch
+		args = []string{"worktree", "add", path, branchName}
+	} else {
+		// Create local tracking branch from remote
+		// git worktree add <path> -b <local-name> --track <remote-branch>
+		args = []string{"worktree", "add", path, "-b", localBranchName, "--track", remoteBranchName}
+	}
+
+	if _, err := r.git(args...); err != nil {
+		return "", false, fmt.Errorf("failed to create worktree: %v", err)
+	}
+
+	// Copy configured files to the new worktree
+	if err := r.CopyConfiguredFiles(path); err != nil {
+		// Log warning but don't fail - worktree was created successfully
+		fmt.Fprintf(os.Stderr, "Warning: failed to copy files: %v\n", err)
+	}
+
+	return path, true, nil
+}
+
+// AmbiguousBranchError is returned when a branch name exists on several
+// remotes and none of them is in the preferred_remotes list
+type AmbiguousBranchError struct {
+	Branch     string
+	Candidates []string // Remote branches, e.g. "origin/feature"
+}
+
+func (e *AmbiguousBranchError) Error() string {
+	return fmt.Sprintf("branch '%s' exists on several remotes: %s (check out one of them or set preferred_remotes)",
+		e.Branch, strings.Join(e.Candidates, ", "))
+}
+
+// resolveRemoteBranch finds the remote branch for name, which is either a
+// full remote branch ("origin/feature") or a branch name looked up on every
+// remote. It returns the remote branch and the local branch name to create,
+// or an empty remote branch when nothing matches
+func resolveRemoteBranch(name string, remotes, remoteBranches []string) (string, string, error) {
+	// Exact remote branch, e.g. "origin/feature"
+	for _, branch := range remoteBranches {
+		if branch != name {
+			continue
+		}
+		for _, remote := range remotes {
+			if local, ok := strings.CutPrefix(branch, remote+"/"); ok {
+				return branch, local, nil
+			}
+		}
+	}
+
+	// Branch name that exists as <remote>/<name> on one or more remotes
+	var candidates []string
+	for _, remote := range remotes {
+		for _, branch := range remoteBranches {
+			if branch == remote+"/"+name {
+				candidates = append(candidates, branch)
+			}
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return "", "", nil
+	case 1:
+		return candidates[0], name, nil
+	}
+
+	if appConfig != nil {
+		for _, preferred := range appConfig.PreferredRemotes {
+			for _, candidate := range candidates {
+				if candidate == preferred+"/"+name {
+					return candidate, name, nil
+				}
+			}
+		}
+	}
+	return "", "", &AmbiguousBranchError{Branch: name, Candidates: candidates}
+}
+
+// CheckoutDetachedWorktree creates (or reuses) a worktree with a detached HEAD
+// at a tag or any commit-ish. Tags get a "tag-<name>" directory, everything
+// else "commit-<short sha>"
+func (r *Repository) CheckoutDetachedWorktree(ref string) (string, bool, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", false, fmt.Errorf("ref cannot be empty")
+	}
+
+	out, err := r.git("rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return "", false, fmt.Errorf("'%s' is not a branch, tag or commit", ref)
+	}
+	commit := strings.TrimSpace(out)
+
+	name := fmt.Sprintf("commit-%.7s", commit)
+	if _, err := r.git("show-ref", "--verify", "--quiet", "refs/tags/"+ref); err == nil {
+		name = "tag-" + ref
+	}
+
+	path, err := r.GenerateWorktreePath(name)
+	if err != nil {
+		return "", false, err
+	}
+
+	// Reuse the worktree if it is still at the same commit
+	existingWorktrees, err := r.ListWorktrees()
+	if err != nil {
+		return "", false, err
+	}
+	for _, wt := range existingWorktrees {
+		if wt.Path != path {
+			continue
+		}
+		if wt.Commit == commit {
+			return path, false, nil
+		}
+		// e.g. a moved tag, or the worktree was switched to something else
+		current := fmt.Sprintf("commit %.7s", wt.Commit)
+		if wt.Branch != "" && wt.Branch != "detached" {
+			current = "branch " + wt.Branch
+		}
+		return "", false, fmt.Errorf("worktree %s is already used for %s, not %s. Remove it first with: worktree-util remove %s", path, current, ref, path)
+	}
+
+	if err := r.addDetachedWorktree(path, commit); err != nil {
+		return "", false, err
+	}
+	return path, true, nil
+}
+
+// addDetachedWorktree creates a worktree at path with commit checked out
+func (r *Repository) addDetachedWorktree(path, commit string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("directory '%s' already exists. Please remove it first with: rm -rf %s", path, path)
+	}
+	if _, err := r.git("worktree", "add", "--detach", path, commit); err != nil {
+		return fmt.Errorf("failed to add worktree: %v", err)
+	}
+	if err := r.CopyConfiguredFiles(path); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to copy files: %v\n", err)
+	}
+	return nil
+}
+
+// pullRequestFetchRef holds a pull request while it is checked against the
+// local branch, before that branch is fast-forwarded
+const pullRequestFetchRef = "refs/worktree-util/fetch/"
+
+// CheckoutPullRequest fetches a pull request from remote into the local
+// branch pr/<number> and creates (or reuses) a worktree for it. The branch
+// is set up to pull from the pull request ref, so the worktree can be
+// updated with a plain git pull. An existing pr/<number> branch is only
+// fast-forwarded; force overwrites it, e.g. after the pull request was rebased.
+func (r *Repository) CheckoutPullRequest(remote string, number int, force bool) (string, bool, error) {
+	if number <= 0 {
+		return "", false, fmt.Errorf("invalid pull request number: %d", number)
+	}
+
+	ref, err := appConfig.PullRequestRef(remote, number)
+	if err != nil {
+		return "", false, err
+	}
+	branch := fmt.Sprintf("pr/%d", number)
+
+	// A branch checked out in a worktree cannot be fetched into
+	existingWorktrees, err := r.ListWorktrees()
+	if err != nil {
+		return "", false, err
+	}
+	for _, wt := range existingWorktrees {
+		if wt.Branch == branch {
+			return wt.Path, false, nil
+		}
+	}
+
+	out, missing := r.git("rev-parse", "--quiet", "--verify", "refs/heads/"+branch)
+	if missing == nil && !force {
+		// Never throw away local commits on an existing branch. Fetch into a
+		// ref of our own, a background fetch may rewrite FETCH_HEAD meanwhile.
+		fetched := pullRequestFetchRef + branch
+		if _, err := r.git("fetch", remote, "+"+ref+":"+fetched); err != nil {
+			return "", false, fmt.Errorf("failed to fetch %s from %s: %v", ref, remote, err)
+		}
+		defer r.git("update-ref", "-d", fetched)
+
+		if _, err := r.git("merge-base", "--is-ancestor", "refs/heads/"+branch, fetched); err != nil {
+			return "", false, fmt.Errorf("branch %s has commits that are not in the pull request and cannot be fast-forwarded (use 'worktree-util pr %d --force' to overwrite it)", branch, number)
+		}
+		if _, err := r.git("update-ref", "refs/heads/"+branch, fetched, strings.TrimSpace(out)); err != nil {
+			return "", false, fmt.Errorf("failed to update branch %s: %v", branch, err)
+		}
+	} else {
+		refspec := ref + ":refs/heads/" + branch
+		if force {
+			refspec = "+" + refspec
+		}
+		if _, err := r.git("fetch", remote, refspec); err != nil {
+			return "", false, fmt.Errorf("failed to fetch %s from %s: %v", ref, remote, err)
+		}
+	}
+	if _, err := r.git("config", "branch."+branch+".remote", remote); err != nil {
+		return "", false, fmt.Errorf("failed to configure branch %s: %v", branch, err)
+	}
+	if _, err := r.git("config", "branch."+branch+".merge", ref); err != nil {
+		return "", false, fmt.Errorf("failed to configure branch %s: %v", branch, err)
+	}
+
+	path, err := r.GenerateWorktreePath(branch)
+	if err != nil {
+		return "", false, err
+	}
+	if err := r.AddWorktree(path, branch, false); err != nil {
+		return "", false, err
+	}
+
+	return path, true, nil
+}
+
+// FetchRemotes fetches the given remotes (all remotes when empty) and prunes
+// remote branches that were deleted upstream
+func (r *Repository) FetchRemotes(remotes []string) error {
+	args := []string{"fetch", "--prune", "--all"}
+	if len(remotes) > 0 {
+		args = append([]string{"fetch", "--prune", "--multiple"}, remotes...)
+	}
+	if _, err := r.git(args...); err != nil {
+		return fmt.Errorf("failed to fetch: %v", err)
+	}
+	return nil
+}
+
+// GetRemotes returns the names of all configured remotes
+func (r *Repository) GetRemotes() ([]string, error) {
+	out, err := r.git("remote")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remotes: %v", err)
+	}
+	return splitLines(out), nil
 }
 
 // CopyConfiguredFiles copies files specified in config from repo root to worktree
-func CopyConfiguredFiles(worktreePath string) error {
+func (r *Repository) CopyConfiguredFiles(worktreePath string) error {
 	if appConfig == nil || len(appConfig.CopyFiles) == 0 {
 		// No files to copy
 		return nil
 	}
 
-	repoRoot, err := GetRepoRoot()
+	repoRoot, err := r.Root()
 	if err != nil {
 		return err
 	}
@@ -1,10 +1,14 @@
 package main
 
 import (
+	"errors"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 // Test Branch struct methods
@@ -24,6 +28,21 @@ func TestBranch_Title(t *testing.T) {
 			branch:   Branch{Name: "origin/feature", IsRemote: true},
 			expected: "🌐 origin/feature",
 		},
+		{
+			name:     "tag",
+			branch:   Branch{Name: "v1.4.2", IsTag: true},
+			expected: "🏷️ v1.4.2",
+		},
+		{
+			name:     "grouped branch",
+			branch:   Branch{Name: "feature", IsRemote: true, Remotes: []string{"origin", "upstream"}},
+			expected: "🌐 feature (origin, upstream)",
+		},
+		{
+			name:     "branch with worktree",
+			branch:   Branch{Name: "feature", WorktreePath: "/repo/.worktrees/feature"},
+			expected: "📌 feature [worktree]",
+		},
 	}
 
 	for _, tt := range tests {
@@ -52,6 +71,21 @@ func TestBranch_Description(t *testing.T) {
 			branch:   Branch{Name: "origin/feature", IsRemote: true},
 			expected: "",
 		},
+		{
+			name:     "author and subject",
+			branch:   Branch{Name: "origin/feature", IsRemote: true, Author: "Jane Doe", Subject: "Add login"},
+			expected: "Jane Doe • Add login",
+		},
+		{
+			name:     "tracking",
+			branch:   Branch{Name: "feature", Upstream: "origin/feature", Ahead: 1, Behind: 2, Subject: "Add login"},
+			expected: "→ origin/feature ↑1 ↓2 • Add login",
+		},
+		{
+			name:     "upstream gone",
+			branch:   Branch{Name: "feature", Upstream: "origin/feature", UpstreamGone: true},
+			expected: "→ origin/feature (gone)",
+		},
 	}
 
 	for _, tt := range tests {
@@ -64,6 +98,30 @@ func TestBranch_Description(t *testing.T) {
 	}
 }
 
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		ago      time.Duration
+		expected string
+	}{
+		{ago: 10 * time.Second, expected: "just now"},
+		{ago: time.Minute, expected: "1 minute ago"},
+		{ago: 3 * time.Hour, expected: "3 hours ago"},
+		{ago: 2 * 24 * time.Hour, expected: "2 days ago"},
+		{ago: 65 * 24 * time.Hour, expected: "2 months ago"},
+		{ago: 800 * 24 * time.Hour, expected: "2 years ago"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if result := relativeTime(now.Add(-tt.ago), now); result != tt.expected {
+				t.Errorf("relativeTime() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestBranch_FilterValue(t *testing.T) {
 	branch := Branch{Name: "feature/test", IsRemote: false}
 	expected := "feature/test"
@@ -118,6 +176,16 @@ func TestWorktree_Description(t *testing.T) {
 			worktree: Worktree{Branch: "", Commit: "abc123def"},
 			contains: "Commit:",
 		},
+		{
+			name:     "dirty and ahead",
+			worktree: Worktree{Branch: "main", Commit: "abc123def", Status: &WorktreeStatus{Changed: 2, Untracked: 1, Ahead: 3}},
+			contains: "| ● 2 changed ? 1 untracked ↑3",
+		},
+		{
+			name:     "clean",
+			worktree: Worktree{Branch: "main", Commit: "abc123def", Status: &WorktreeStatus{}},
+			contains: "| ✓ clean",
+		},
 	}
 
 	for _, tt := range tests {
@@ -130,6 +198,45 @@ func TestWorktree_Description(t *testing.T) {
 	}
 }
 
+func TestWorktree_Badges(t *testing.T) {
+	tests := []struct {
+		name        string
+		worktree    Worktree
+		title       string
+		description string
+	}{
+		{
+			name:        "locked with reason",
+			worktree:    Worktree{Path: "/wt", Branch: "x", Commit: "abc123def", Locked: true, LockReason: "usb drive"},
+			title:       "🔒 locked",
+			description: "Locked: usb drive",
+		},
+		{
+			name:        "prunable",
+			worktree:    Worktree{Path: "/wt", Branch: "x", Commit: "abc123def", Prunable: true, PrunableReason: "gitdir file points to non-existent location"},
+			title:       "⚠ prunable",
+			description: "Prunable: gitdir file points to non-existent location",
+		},
+		{
+			name:        "bare",
+			worktree:    Worktree{Path: "/repo.git", IsBare: true, IsMain: true},
+			title:       "[bare]",
+			description: "Bare repository",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if title := tt.worktree.Title(); !strings.Contains(title, tt.title) {
+				t.Errorf("Title() = %v, should contain %v", title, tt.title)
+			}
+			if desc := tt.worktree.Description(); !strings.Contains(desc, tt.description) {
+				t.Errorf("Description() = %v, should contain %v", desc, tt.description)
+			}
+		})
+	}
+}
+
 func TestWorktree_FilterValue(t *testing.T) {
 	worktree := Worktree{Path: "/path/to/repo", Branch: "main"}
 	result := worktree.FilterValue()
@@ -140,124 +247,242 @@ func TestWorktree_FilterValue(t *testing.T) {
 
 // Test GenerateWorktreePath
 func TestGenerateWorktreePath(t *testing.T) {
+	withConfig(t, nil)
+
+	repo := NewRepository("/path/to/repo", newFakeRunner().on("rev-parse --show-toplevel", "/path/to/repo\n"))
+
 	tests := []struct {
-		name          string
-		branch        string
-		shouldContain string
+		name     string
+		branch   string
+		expected string
 	}{
 		{
-			name:          "simple branch",
-			branch:        "feature",
-			shouldContain: "feature",
+			name:     "simple branch",
+			branch:   "feature",
+			expected: "feature",
 		},
 		{
-			name:          "branch with slash",
-			branch:        "feature/new-feature",
-			shouldContain: "feature-new-feature",
+			name:     "branch with slash",
+			branch:   "feature/new-feature",
+			expected: "feature-new-feature",
 		},
 		{
-			name:          "branch with spaces",
-			branch:        "my feature",
-			shouldContain: "my-feature",
+			name:     "branch with spaces",
+			branch:   "my feature",
+			expected: "my-feature",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := GenerateWorktreePath(tt.branch)
+			result, err := repo.GenerateWorktreePath(tt.branch)
 			if err != nil {
 				t.Fatalf("GenerateWorktreePath() error = %v", err)
 			}
-			if !strings.Contains(result, tt.shouldContain) {
-				t.Errorf("GenerateWorktreePath() = %v, should contain %v", result, tt.shouldContain)
-			}
-			if !strings.Contains(result, ".worktrees") {
-				t.Errorf("GenerateWorktreePath() = %v, should contain .worktrees", result)
+			expected := filepath.Join("/path/to/repo", ".worktrees", tt.expected)
+			if result != expected {
+				t.Errorf("GenerateWorktreePath() = %v, want %v", result, expected)
 			}
 		})
 	}
 }
 
-// Test GetRepoRoot
-func TestGetRepoRoot(t *testing.T) {
-	root, err := GetRepoRoot()
+// Test GenerateWorktreePath honors worktree_dir from config
+func TestGenerateWorktreePath_ConfiguredDir(t *testing.T) {
+	withConfig(t, &Config{WorktreeDir: "../wt"})
+
+	repo := NewRepository("/path/to/repo", newFakeRunner().on("rev-parse --show-toplevel", "/path/to/repo\n"))
+
+	result, err := repo.GenerateWorktreePath("feature")
+	if err != nil {
+		t.Fatalf("GenerateWorktreePath() error = %v", err)
+	}
+	if expected := filepath.Join("/path/to", "wt", "feature"); result != expected {
+		t.Errorf("GenerateWorktreePath() = %v, want %v", result, expected)
+	}
+}
+
+// Test Root
+func TestRepository_Root(t *testing.T) {
+	dir := newTestRepo(t)
+	sub := filepath.Join(dir, "sub")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatalf("Failed to create subdirectory: %v", err)
+	}
+
+	root, err := NewRepository(sub, ExecRunner{}).Root()
 	if err != nil {
-		t.Fatalf("GetRepoRoot() error = %v", err)
+		t.Fatalf("Root() error = %v", err)
 	}
-	if root == "" {
-		t.Error("GetRepoRoot() returned empty string")
+	if root != dir {
+		t.Errorf("Root() = %v, want %v", root, dir)
 	}
-	if !strings.Contains(root, "worktree-util") {
-		t.Errorf("GetRepoRoot() = %v, should contain worktree-util", root)
+}
+
+// Test Root outside of a git repository
+func TestRepository_Root_NotARepository(t *testing.T) {
+	fake := newFakeRunner().onError("rev-parse --show-toplevel", "fatal: not a git repository (or any of the parent directories): .git\n")
+
+	_, err := NewRepository("/tmp", fake).Root()
+	if err == nil || err.Error() != "not a git repository" {
+		t.Errorf("Root() error = %v, want 'not a git repository'", err)
 	}
 }
 
 // Test GetLocalBranches
 func TestGetLocalBranches(t *testing.T) {
-	branches, err := GetLocalBranches()
+	repo := NewRepository("/repo", newFakeRunner().on("branch --format=%(refname:short)", "main\nfeature/login\n"))
+
+	branches, err := repo.GetLocalBranches()
 	if err != nil {
 		t.Fatalf("GetLocalBranches() error = %v", err)
 	}
-	if len(branches) == 0 {
-		t.Error("GetLocalBranches() returned no branches")
+	if !reflect.DeepEqual(branches, []string{"main", "feature/login"}) {
+		t.Errorf("GetLocalBranches() = %v, want [main feature/login]", branches)
 	}
+}
 
-	// Should contain at least the current branch
-	found := false
-	for _, branch := range branches {
-		if branch != "" {
-			found = true
-			break
-		}
+// Test GetRemoteBranches
+func TestGetRemoteBranches(t *testing.T) {
+	repo := NewRepository("/repo", newFakeRunner().on("branch -r --format=%(refname:short)", "origin/HEAD\norigin/main\nupstream/feature\n"))
+
+	branches, err := repo.GetRemoteBranches()
+	if err != nil {
+		t.Fatalf("GetRemoteBranches() error = %v", err)
 	}
-	if !found {
-		t.Error("GetLocalBranches() returned only empty branch names")
+
+	// HEAD references are filtered out
+	if !reflect.DeepEqual(branches, []string{"origin/main", "upstream/feature"}) {
+		t.Errorf("GetRemoteBranches() = %v, want [origin/main upstream/feature]", branches)
 	}
 }
 
-// Test GetRemoteBranches
-func TestGetRemoteBranches(t *testing.T) {
-	branches, err := GetRemoteBranches()
+// Test GetRemoteBranches in a repository without remotes
+func TestGetRemoteBranches_NoRemotes(t *testing.T) {
+	repo := NewRepository("/repo", newFakeRunner().on("branch -r --format=%(refname:short)", ""))
+
+	branches, err := repo.GetRemoteBranches()
 	if err != nil {
 		t.Fatalf("GetRemoteBranches() error = %v", err)
 	}
+	if len(branches) != 0 {
+		t.Errorf("GetRemoteBranches() = %v, want none", branches)
+	}
+}
 
-	// Remote branches might be empty in some repos, so we just check it doesn't error
-	// and if there are branches, they should have proper format
-	for _, branch := range branches {
-		if !strings.Contains(branch, "/") && branch != "" {
-			t.Errorf("Remote branch %v should contain '/'", branch)
-		}
-		if strings.Contains(branch, "HEAD") {
-			t.Errorf("Remote branches should not contain HEAD, got %v", branch)
-		}
+// forEachBranchRef is the git command GetAllBranches runs
+const forEachBranchRef = "for-each-ref --sort=-committerdate --format=" + branchRefFormat + " refs/heads refs/remotes"
+
+// branchRefs renders for-each-ref output for GetAllBranches from records
+// of the branchRefFormat fields
+func branchRefs(records ...[7]string) string {
+	var b strings.Builder
+	for _, record := range records {
+		b.WriteString(strings.Join(record[:], "\x00") + "\n")
 	}
+	return b.String()
 }
 
 // Test GetAllBranches
 func TestGetAllBranches(t *testing.T) {
-	branches, err := GetAllBranches()
+	fake := newFakeRunner().on("remote", "origin\n").on(forEachBranchRef, branchRefs(
+		[7]string{"refs/heads/feature", "1700000200", "Jane Doe", "origin/feature", "ahead 1, behind 2", "Add login", "/repo/.worktrees/feature"},
+		[7]string{"refs/remotes/origin/HEAD", "1700000100", "Jane Doe", "", "", "Initial commit", ""},
+		[7]string{"refs/remotes/origin/main", "1700000100", "Jane Doe", "", "", "Initial commit", ""},
+		[7]string{"refs/heads/main", "1700000000", "John Roe", "origin/main", "gone", "Old commit", "/repo"},
+	))
+	repo := NewRepository("/repo", fake)
+
+	branches, err := repo.GetAllBranches()
 	if err != nil {
 		t.Fatalf("GetAllBranches() error = %v", err)
 	}
-	if len(branches) == 0 {
-		t.Error("GetAllBranches() returned no branches")
+
+	// Sorted by git, symbolic remote HEADs are skipped
+	expected := []Branch{
+		{Name: "feature", CommitDate: time.Unix(1700000200, 0), Author: "Jane Doe", Subject: "Add login",
+			Upstream: "origin/feature", Ahead: 1, Behind: 2, WorktreePath: "/repo/.worktrees/feature"},
+		{Name: "origin/main", IsRemote: true, Remote: "origin", CommitDate: time.Unix(1700000100, 0), Author: "Jane Doe", Subject: "Initial commit"},
+		{Name: "main", CommitDate: time.Unix(1700000000, 0), Author: "John Roe", Subject: "Old commit",
+			Upstream: "origin/main", UpstreamGone: true, WorktreePath: "/repo"},
+	}
+	if !reflect.DeepEqual(branches, expected) {
+		t.Errorf("GetAllBranches() = %+v, want %+v", branches, expected)
+	}
+}
+
+// Test local and remote namesakes are grouped into one branch
+func TestGroupBranches(t *testing.T) {
+	branches := []Branch{
+		{Name: "origin/feature", IsRemote: true, Remote: "origin", Subject: "remote commit"},
+		{Name: "feature", Subject: "local commit", Ahead: 1},
+		{Name: "upstream/feature", IsRemote: true, Remote: "upstream"},
+		{Name: "upstream/only-remote", IsRemote: true, Remote: "upstream"},
+		{Name: "origin/only-remote", IsRemote: true, Remote: "origin"},
+		{Name: "local-only"},
+		{Name: "v1.0.0", IsTag: true},
+	}
+
+	expected := []Branch{
+		{Name: "feature", Subject: "local commit", Ahead: 1, Remotes: []string{"origin", "upstream"}},
+		{Name: "only-remote", IsRemote: true, Remote: "upstream", Remotes: []string{"upstream", "origin"}},
+		{Name: "local-only"},
+		{Name: "v1.0.0", IsTag: true},
+	}
+	if result := groupBranches(branches); !reflect.DeepEqual(result, expected) {
+		t.Errorf("groupBranches() = %+v, want %+v", result, expected)
 	}
+}
 
-	// Check that we have both local and remote branches marked correctly
-	hasLocal := false
+// Test GetAllBranches against a real repository
+func TestGetAllBranches_Repository(t *testing.T) {
+	dir := newTestRepo(t)
+	runGit(t, dir, "branch", "feature")
+	runGit(t, dir, "worktree", "add", "-q", filepath.Join(dir, ".worktrees", "feature"), "feature")
+	runGit(t, dir, "branch", "idle")
 
+	branches, err := NewRepository(dir, ExecRunner{}).GetAllBranches()
+	if err != nil {
+		t.Fatalf("GetAllBranches() error = %v", err)
+	}
+	if len(branches) != 3 {
+		t.Fatalf("GetAllBranches() = %+v, want 3 branches", branches)
+	}
 	for _, branch := range branches {
-		if branch.Name == "" {
-			t.Error("GetAllBranches() returned branch with empty name")
+		if branch.Author != "Test" || branch.Subject != "initial commit" || branch.CommitDate.IsZero() {
+			t.Errorf("branch %s is missing commit metadata: %+v", branch.Name, branch)
 		}
-		if !branch.IsRemote {
-			hasLocal = true
+		hasWorktree := branch.Name == "main" || branch.Name == "feature"
+		if (branch.WorktreePath != "") != hasWorktree {
+			t.Errorf("branch %s WorktreePath = %q", branch.Name, branch.WorktreePath)
 		}
 	}
+}
+
+// Test GetAllBranches surfaces git errors
+func TestGetAllBranches_Error(t *testing.T) {
+	fake := newFakeRunner().onError(forEachBranchRef, "fatal: not a git repository\n")
 
-	if !hasLocal {
-		t.Error("GetAllBranches() should return at least one local branch")
+	if _, err := NewRepository("/repo", fake).GetAllBranches(); err == nil {
+		t.Error("GetAllBranches() should return git errors")
+	}
+}
+
+// Test ListWorktrees against recorded porcelain output
+func TestListWorktrees(t *testing.T) {
+	fake := newFakeRunner().
+		on("rev-parse --show-toplevel", "/path/to/repo\n").
+		on("worktree list --porcelain", "worktree /path/to/repo\nHEAD abc123\nbranch refs/heads/main\n\nworktree /path/to/repo/.worktrees/feature\nHEAD def456\nbranch refs/heads/feature\n\n")
+
+	worktrees, err := NewRepository("/path/to/repo", fake).ListWorktrees()
+	if err != nil {
+		t.Fatalf("ListWorktrees() error = %v", err)
+	}
+	if len(worktrees) != 2 {
+		t.Fatalf("ListWorktrees() returned %d worktrees, want 2", len(worktrees))
+	}
+	if worktrees[1].Branch != "feature" || worktrees[1].IsMain {
+		t.Errorf("unexpected second worktree: %+v", worktrees[1])
 	}
 }
 
@@ -318,6 +543,57 @@ branch refs/heads/main
 	}
 }
 
+// Test parseWorktrees with the full porcelain record
+func TestParseWorktrees_States(t *testing.T) {
+	input := `worktree /path/to/repo.git
+bare
+
+worktree /path/to/detached
+HEAD abc123
+detached
+
+worktree /path/to/locked
+HEAD def456
+branch refs/heads/locked
+locked on removable drive
+
+worktree /path/to/locked-no-reason
+HEAD def456
+branch refs/heads/other
+locked
+
+worktree /path/to/gone
+HEAD 789abc
+branch refs/heads/gone
+prunable gitdir file points to non-existent location
+
+`
+
+	worktrees := parseWorktrees(input)
+	if len(worktrees) != 5 {
+		t.Fatalf("parseWorktrees() returned %d worktrees, want 5", len(worktrees))
+	}
+
+	if !worktrees[0].IsBare || !worktrees[0].IsMain {
+		t.Errorf("first worktree should be bare and main: %+v", worktrees[0])
+	}
+	if worktrees[1].Branch != "detached" || worktrees[1].Commit != "abc123" {
+		t.Errorf("second worktree should be detached at abc123: %+v", worktrees[1])
+	}
+	if !worktrees[2].Locked || worktrees[2].LockReason != "on removable drive" {
+		t.Errorf("third worktree should be locked with reason: %+v", worktrees[2])
+	}
+	if !worktrees[3].Locked || worktrees[3].LockReason != "" {
+		t.Errorf("fourth worktree should be locked without reason: %+v", worktrees[3])
+	}
+	if !worktrees[4].Prunable || worktrees[4].PrunableReason != "gitdir file points to non-existent location" {
+		t.Errorf("fifth worktree should be prunable with reason: %+v", worktrees[4])
+	}
+	if worktrees[4].Locked || worktrees[2].Prunable {
+		t.Error("states should not leak between worktrees")
+	}
+}
+
 // Test parseWorktrees with empty input
 func TestParseWorktrees_Empty(t *testing.T) {
 	input := ""
@@ -379,7 +655,7 @@ func TestCopyConfiguredFiles_NoConfig(t *testing.T) {
 	appConfig = nil
 
 	tempDir := t.TempDir()
-	err := CopyConfiguredFiles(tempDir)
+	err := NewRepository(tempDir, newFakeRunner()).CopyConfiguredFiles(tempDir)
 	if err != nil {
 		t.Errorf("CopyConfiguredFiles() with nil config should not error, got: %v", err)
 	}
@@ -398,7 +674,7 @@ func TestCopyConfiguredFiles_EmptyList(t *testing.T) {
 	}
 
 	tempDir := t.TempDir()
-	err := CopyConfiguredFiles(tempDir)
+	err := NewRepository(tempDir, newFakeRunner()).CopyConfiguredFiles(tempDir)
 	if err != nil {
 		t.Errorf("CopyConfiguredFiles() with empty list should not error, got: %v", err)
 	}
@@ -435,9 +711,22 @@ func TestCopyConfiguredFiles_ValidFiles(t *testing.T) {
 		t.Fatalf("Failed to create test file 2: %v", err)
 	}
 
-	// Note: This test would need to mock GetRepoRoot() to work properly
-	// For now, we'll test the copyFile function which is the core functionality
-	t.Skip("Skipping integration test - would require mocking GetRepoRoot()")
+	appConfig = &Config{
+		WorktreeDir: ".worktrees",
+		CopyFiles:   []string{testFile1, testFile2},
+	}
+
+	worktreePath := filepath.Join(tempDir, "worktree")
+	repo := NewRepository(repoRoot, newFakeRunner().on("rev-parse --show-toplevel", repoRoot+"\n"))
+	if err := repo.CopyConfiguredFiles(worktreePath); err != nil {
+		t.Fatalf("CopyConfiguredFiles() error = %v", err)
+	}
+
+	for _, file := range []string{testFile1, testFile2} {
+		if _, err := os.Stat(filepath.Join(worktreePath, file)); err != nil {
+			t.Errorf("%s was not copied: %v", file, err)
+		}
+	}
 }
 
 // Test CopyConfiguredFiles with missing files (should skip gracefully)
@@ -446,8 +735,20 @@ func TestCopyConfiguredFiles_MissingFiles(t *testing.T) {
 	originalConfig := appConfig
 	defer func() { appConfig = originalConfig }()
 
-	// Note: This test would need to mock GetRepoRoot() to work properly
-	t.Skip("Skipping integration test - would require mocking GetRepoRoot()")
+	repoRoot := t.TempDir()
+	appConfig = &Config{
+		WorktreeDir: ".worktrees",
+		CopyFiles:   []string{".env.missing"},
+	}
+
+	worktreePath := t.TempDir()
+	repo := NewRepository(repoRoot, newFakeRunner().on("rev-parse --show-toplevel", repoRoot+"\n"))
+	if err := repo.CopyConfiguredFiles(worktreePath); err != nil {
+		t.Errorf("CopyConfiguredFiles() should skip missing files, got: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(worktreePath, ".env.missing")); !os.IsNotExist(err) {
+		t.Error("missing file should not be created")
+	}
 }
 
 // Test AddWorktree with existing directory
@@ -462,7 +763,8 @@ func TestAddWorktree_ExistingDirectory(t *testing.T) {
 	}
 
 	// Try to create a worktree at the existing path
-	err := AddWorktree(existingPath, "test-branch", true)
+	fake := newFakeRunner()
+	err := NewRepository(tempDir, fake).AddWorktree(existingPath, "test-branch", true)
 
 	// Should return an error
 	if err == nil {
@@ -473,4 +775,468 @@ func TestAddWorktree_ExistingDirectory(t *testing.T) {
 	if !strings.Contains(err.Error(), "already exists") {
 		t.Errorf("Error should mention directory exists, got: %v", err)
 	}
+
+	// Git should never be invoked
+	if len(fake.calls) != 0 {
+		t.Errorf("AddWorktree() should not run git, calls = %v", fake.calls)
+	}
+}
+
+// Test AddWorktree passes the base ref to git
+func TestAddWorktreeWithOptions_Base(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "feature")
+	fake := newFakeRunner().on("worktree add --no-track -b feature "+path+" origin/main", "")
+
+	err := NewRepository("/repo", fake).AddWorktreeWithOptions(path, "feature", true, AddOptions{Base: "origin/main", NoCopy: true})
+	if err != nil {
+		t.Fatalf("AddWorktreeWithOptions() error = %v", err)
+	}
+	if !fake.called("worktree add --no-track -b feature " + path + " origin/main") {
+		t.Errorf("unexpected git calls: %v", fake.calls)
+	}
+}
+
+// Test DefaultBranch follows origin/HEAD
+func TestDefaultBranch(t *testing.T) {
+	upstream := newTestRepo(t)
+	runGit(t, upstream, "branch", "-m", "trunk")
+
+	dir := newTestRepo(t)
+	repo := NewRepository(dir, ExecRunner{})
+	if _, err := repo.DefaultBranch(); err == nil {
+		t.Error("DefaultBranch() should fail without a remote")
+	}
+
+	runGit(t, dir, "remote", "add", "origin", upstream)
+	runGit(t, dir, "fetch", "-q", "origin")
+	runGit(t, dir, "remote", "set-head", "origin", "-a")
+
+	branch, err := repo.DefaultBranch()
+	if err != nil {
+		t.Fatalf("DefaultBranch() error = %v", err)
+	}
+	if branch != "origin/trunk" {
+		t.Errorf("DefaultBranch() = %v, want origin/trunk", branch)
+	}
+}
+
+// Test BaseRefs combines branches and tags
+func TestBaseRefs(t *testing.T) {
+	fake := newFakeRunner().
+		on("remote", "origin\n").
+		on(forEachBranchRef, branchRefs(
+			[7]string{"refs/heads/main", "1700000000", "", "", "", "", ""},
+			[7]string{"refs/remotes/origin/HEAD", "1700000000", "", "", "", "", ""},
+			[7]string{"refs/remotes/origin/main", "1700000000", "", "", "", "", ""},
+		)).
+		on("tag --list --sort=-creatordate", "v1.1.0\nv1.0.0\n")
+
+	refs, err := NewRepository("/repo", fake).BaseRefs()
+	if err != nil {
+		t.Fatalf("BaseRefs() error = %v", err)
+	}
+	expected := []string{"main", "origin/main", "v1.1.0", "v1.0.0"}
+	if !reflect.DeepEqual(refs, expected) {
+		t.Errorf("BaseRefs() = %v, want %v", refs, expected)
+	}
+}
+
+// Test parseStatusV2 against git status --porcelain=v2 --branch output
+func TestParseStatusV2(t *testing.T) {
+	output := `# branch.oid 1234567890abcdef
+# branch.head feature
+# branch.upstream origin/feature
+# branch.ab +2 -1
+1 .M N... 100644 100644 100644 abc abc README.md
+1 A. N... 000000 100644 100644 000 abc new.go
+2 R. N... 100644 100644 100644 abc abc R100 renamed.go	old.go
+u UU N... 100644 100644 100644 100644 abc abc abc conflict.go
+? notes.txt
+`
+
+	expected := WorktreeStatus{Changed: 3, Untracked: 1, Conflicts: 1, Upstream: "origin/feature", Ahead: 2, Behind: 1}
+	if status := parseStatusV2(output); status != expected {
+		t.Errorf("parseStatusV2() = %+v, want %+v", status, expected)
+	}
+	if !expected.IsDirty() || (WorktreeStatus{Ahead: 1}).IsDirty() {
+		t.Error("IsDirty() should only consider files")
+	}
+}
+
+// Test stash entries are counted per branch
+func TestCountStashesByBranch(t *testing.T) {
+	output := "WIP on main: abc123 commit\nOn feature/x: my stash\nWIP on main: def456 other\nWIP on (no branch): abc123 commit\n"
+
+	expected := map[string]int{"main": 2, "feature/x": 1, "(no branch)": 1}
+	if counts := countStashesByBranch(output); !reflect.DeepEqual(counts, expected) {
+		t.Errorf("countStashesByBranch() = %v, want %v", counts, expected)
+	}
+}
+
+// Test GetWorktreeStatuses against real worktrees
+func TestGetWorktreeStatuses(t *testing.T) {
+	dir := newTestRepo(t)
+	if err := os.WriteFile(filepath.Join(dir, ".git", "info", "exclude"), []byte(".worktrees/\n"), 0644); err != nil {
+		t.Fatalf("Failed to exclude .worktrees: %v", err)
+	}
+	feature := addTestWorktree(t, dir, "feature")
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("changed\n"), 0644); err != nil {
+		t.Fatalf("Failed to modify README: %v", err)
+	}
+	runGit(t, dir, "stash", "-q")
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("changed again\n"), 0644); err != nil {
+		t.Fatalf("Failed to modify README: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(feature, "untracked.txt"), []byte("new\n"), 0644); err != nil {
+		t.Fatalf("Failed to create untracked file: %v", err)
+	}
+	repo := NewRepository(dir, ExecRunner{})
+
+	worktrees, err := repo.ListWorktrees()
+	if err != nil {
+		t.Fatalf("ListWorktrees() error = %v", err)
+	}
+	worktrees = append(worktrees, Worktree{Path: filepath.Join(dir, ".worktrees", "gone"), Prunable: true})
+
+	statuses := repo.GetWorktreeStatuses(worktrees)
+	if len(statuses) != 2 {
+		t.Fatalf("GetWorktreeStatuses() = %v, want the two existing worktrees", statuses)
+	}
+	if status := statuses[dir]; status.Changed != 1 || status.Stashes != 1 || status.Untracked != 0 {
+		t.Errorf("main worktree status = %+v, want 1 changed and 1 stash", status)
+	}
+	if status := statuses[feature]; status.Untracked != 1 || status.Changed != 0 || status.Stashes != 0 {
+		t.Errorf("feature worktree status = %+v, want 1 untracked file", status)
+	}
+}
+
+func TestBranchStashes(t *testing.T) {
+	fake := newFakeRunner().on("stash list --format=%gd%x00%gs",
+		"stash@{0}\x00On feature: wip\nstash@{1}\x00WIP on main: abc123 initial\nstash@{2}\x00WIP on feature: def456 login\n")
+
+	stashes, err := NewRepository("/repo", fake).BranchStashes("feature")
+	if err != nil {
+		t.Fatalf("BranchStashes() error = %v", err)
+	}
+	expected := []string{"stash@{0}: On feature: wip", "stash@{2}: WIP on feature: def456 login"}
+	if !reflect.DeepEqual(stashes, expected) {
+		t.Errorf("BranchStashes() = %v, want %v", stashes, expected)
+	}
+}
+
+// Test GetWorktreePreview shows log, status and diffstat against the upstream
+func TestGetWorktreePreview(t *testing.T) {
+	dir := newTestRepo(t)
+	feature := addTestWorktree(t, dir, "feature")
+	runGit(t, feature, "branch", "--set-upstream-to=main")
+	if err := os.WriteFile(filepath.Join(feature, "login.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	runGit(t, feature, "add", "login.go")
+	runGit(t, feature, "commit", "-q", "-m", "add login")
+	if err := os.WriteFile(filepath.Join(feature, "notes.txt"), []byte("todo\n"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	repo := NewRepository(dir, ExecRunner{})
+
+	preview, err := repo.GetWorktreePreview(Worktree{Path: feature, Branch: "feature"})
+	if err != nil {
+		t.Fatalf("GetWorktreePreview() error = %v", err)
+	}
+	for _, want := range []string{"Recent commits", "add login", "?? notes.txt", "Changes against main", "login.go | 1 +"} {
+		if !strings.Contains(preview, want) {
+			t.Errorf("GetWorktreePreview() should contain %q, got:\n%s", want, preview)
+		}
+	}
+
+	// Without an upstream or origin/HEAD there is nothing to diff against
+	preview, err = repo.GetWorktreePreview(Worktree{Path: dir, Branch: "main", IsMain: true})
+	if err != nil {
+		t.Fatalf("GetWorktreePreview() error = %v", err)
+	}
+	if !strings.Contains(preview, "No upstream or default branch") {
+		t.Errorf("GetWorktreePreview() should explain the missing base, got:\n%s", preview)
+	}
+}
+
+// newTestRepo creates a temporary git repository with an initial commit
+// on branch "main" and returns its path
+func newTestRepo(t *testing.T) string {
+	t.Helper()
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to resolve temp dir: %v", err)
+	}
+
+	runGit(t, dir, "init", "-q", "-b", "main")
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("test\n"), 0644); err != nil {
+		t.Fatalf("Failed to create README: %v", err)
+	}
+	runGit(t, dir, "add", "README.md")
+	runGit(t, dir, "commit", "-q", "-m", "initial commit")
+
+	return dir
+}
+
+// runGit runs a git command in dir and fails the test on error
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	args = append([]string{"-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// addTestWorktree adds a worktree for the new branch under .worktrees in the
+// repository at dir and returns its path
+func addTestWorktree(t *testing.T, dir, branch string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, ".worktrees", strings.ReplaceAll(branch, "/", "-"))
+	runGit(t, dir, "worktree", "add", "-q", "-b", branch, path)
+	return path
+}
+
+// withConfig sets appConfig for the duration of the test
+func withConfig(t *testing.T, config *Config) {
+	t.Helper()
+
+	original := appConfig
+	t.Cleanup(func() { appConfig = original })
+	appConfig = config
+}
+
+// withDefaultConfig sets appConfig to the defaults for the duration of the test
+func withDefaultConfig(t *testing.T) {
+	t.Helper()
+	withConfig(t, DefaultConfig())
+}
+
+// Test CheckoutBranchWorktree reports created vs reused worktrees
+func TestCheckoutBranchWorktree(t *testing.T) {
+	withDefaultConfig(t)
+
+	dir := newTestRepo(t)
+	runGit(t, dir, "branch", "feature")
+	repo := NewRepository(dir, ExecRunner{})
+
+	path, created, err := repo.CheckoutBranchWorktree("feature")
+	if err != nil {
+		t.Fatalf("CheckoutBranchWorktree() error = %v", err)
+	}
+	if !created {
+		t.Error("CheckoutBranchWorktree() should report a new worktree")
+	}
+	if expected := filepath.Join(dir, ".worktrees", "feature"); path != expected {
+		t.Errorf("CheckoutBranchWorktree() path = %v, want %v", path, expected)
+	}
+
+	again, created, err := repo.CheckoutBranchWorktree("feature")
+	if err != nil {
+		t.Fatalf("CheckoutBranchWorktree() second call error = %v", err)
+	}
+	if created {
+		t.Error("CheckoutBranchWorktree() should reuse the existing worktree")
+	}
+	if again != path {
+		t.Errorf("CheckoutBranchWorktree() reused path = %v, want %v", again, path)
+	}
+
+	if _, _, err := repo.CheckoutBranchWorktree("does-not-exist"); err == nil {
+		t.Error("CheckoutBranchWorktree() should fail for unknown branch")
+	}
+}
+
+// Test remote branches are matched exactly and ambiguity is reported
+func TestResolveRemoteBranch(t *testing.T) {
+	withDefaultConfig(t)
+
+	remotes := []string{"origin", "upstream"}
+	remoteBranches := []string{"origin/feature", "upstream/feature", "origin/feature/login", "upstream/only-upstream"}
+
+	tests := []struct {
+		name      string
+		branch    string
+		preferred []string
+		remote    string
+		local     string
+		ambiguous bool
+	}{
+		{name: "full remote branch", branch: "origin/feature", remote: "origin/feature", local: "feature"},
+		{name: "single remote", branch: "only-upstream", remote: "upstream/only-upstream", local: "only-upstream"},
+		{name: "branch with slash", branch: "feature/login", remote: "origin/feature/login", local: "feature/login"},
+		{name: "no suffix match", branch: "login"},
+		{name: "ambiguous", branch: "feature", ambiguous: true},
+		{name: "preferred remote", branch: "feature", preferred: []string{"upstream", "origin"}, remote: "upstream/feature", local: "feature"},
+		{name: "preferred remote without the branch", branch: "feature", preferred: []string{"fork"}, ambiguous: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appConfig = &Config{PreferredRemotes: tt.preferred}
+
+			remote, local, err := resolveRemoteBranch(tt.branch, remotes, remoteBranches)
+			var ambiguous *AmbiguousBranchError
+			if tt.ambiguous {
+				if !errors.As(err, &ambiguous) {
+					t.Fatalf("resolveRemoteBranch() error = %v, want AmbiguousBranchError", err)
+				}
+				if !reflect.DeepEqual(ambiguous.Candidates, []string{"origin/feature", "upstream/feature"}) {
+					t.Errorf("candidates = %v, want [origin/feature upstream/feature]", ambiguous.Candidates)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveRemoteBranch() error = %v", err)
+			}
+			if remote != tt.remote || local != tt.local {
+				t.Errorf("resolveRemoteBranch() = (%q, %q), want (%q, %q)", remote, local, tt.remote, tt.local)
+			}
+		})
+	}
+}
+
+// Test CheckoutBranchWorktree with a branch on two remotes
+func TestCheckoutBranchWorktree_MultipleRemotes(t *testing.T) {
+	withDefaultConfig(t)
+
+	upstream := newTestRepo(t)
+	runGit(t, upstream, "branch", "shared")
+
+	dir := newTestRepo(t)
+	runGit(t, dir, "remote", "add", "origin", upstream)
+	runGit(t, dir, "remote", "add", "upstream", upstream)
+	runGit(t, dir, "fetch", "-q", "--all")
+	repo := NewRepository(dir, ExecRunner{})
+
+	_, _, err := repo.CheckoutBranchWorktree("shared")
+	if err == nil || !strings.Contains(err.Error(), "origin/shared, upstream/shared") {
+		t.Fatalf("CheckoutBranchWorktree() should list both candidates, got: %v", err)
+	}
+
+	appConfig.PreferredRemotes = []string{"upstream"}
+	path, _, err := repo.CheckoutBranchWorktree("shared")
+	if err != nil {
+		t.Fatalf("CheckoutBranchWorktree() error = %v", err)
+	}
+	if upstreamRef := runGit(t, path, "rev-parse", "--abbrev-ref", "@{upstream}"); upstreamRef != "upstream/shared" {
+		t.Errorf("worktree upstream = %v, want upstream/shared", upstreamRef)
+	}
+}
+
+// Test FetchRemotes fetches all or only the given remotes
+func TestFetchRemotes(t *testing.T) {
+	fake := newFakeRunner().
+		on("fetch --prune --all", "").
+		on("fetch --prune --multiple origin upstream", "")
+	repo := NewRepository("/repo", fake)
+
+	if err := repo.FetchRemotes(nil); err != nil {
+		t.Fatalf("FetchRemotes(nil) error = %v", err)
+	}
+	if err := repo.FetchRemotes([]string{"origin", "upstream"}); err != nil {
+		t.Fatalf("FetchRemotes() error = %v", err)
+	}
+	if !fake.called("fetch --prune --all") || !fake.called("fetch --prune --multiple origin upstream") {
+		t.Errorf("unexpected git calls: %v", fake.calls)
+	}
+}
+
+// Test tags and commits are checked out as detached worktrees
+func TestCheckoutDetachedWorktree(t *testing.T) {
+	withDefaultConfig(t)
+
+	dir := newTestRepo(t)
+	runGit(t, dir, "tag", "v1.4.2")
+	tagged := runGit(t, dir, "rev-parse", "HEAD")
+	runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "second commit")
+	head := runGit(t, dir, "rev-parse", "HEAD")
+	repo := NewRepository(dir, ExecRunner{})
+
+	// Tags fall through from CheckoutBranchWorktree
+	path, created, err := repo.CheckoutBranchWorktree("v1.4.2")
+	if err != nil {
+		t.Fatalf("CheckoutBranchWorktree() error = %v", err)
+	}
+	if !created {
+		t.Error("CheckoutBranchWorktree() should report a new worktree")
+	}
+	if expected := filepath.Join(dir, ".worktrees", "tag-v1.4.2"); path != expected {
+		t.Errorf("tag worktree path = %v, want %v", path, expected)
+	}
+	if ref := runGit(t, path, "rev-parse", "--abbrev-ref", "HEAD"); ref != "HEAD" {
+		t.Errorf("tag worktree is on %q, want a detached HEAD", ref)
+	}
+	if commit := runGit(t, path, "rev-parse", "HEAD"); commit != tagged {
+		t.Errorf("tag worktree HEAD = %v, want %v", commit, tagged)
+	}
+
+	if _, created, err := repo.CheckoutDetachedWorktree("v1.4.2"); err != nil || created {
+		t.Errorf("CheckoutDetachedWorktree() should reuse the tag worktree, created = %v, err = %v", created, err)
+	}
+
+	// A moved tag cannot reuse the directory of the old worktree
+	runGit(t, dir, "tag", "-f", "v1.4.2", head)
+	if _, _, err := repo.CheckoutDetachedWorktree("v1.4.2"); err == nil || !strings.Contains(err.Error(), path+" is already used for commit "+tagged[:7]) {
+		t.Errorf("CheckoutDetachedWorktree() should name the worktree in the way, got: %v", err)
+	}
+
+	// Branches can be checked out detached too, named after the commit
+	path, _, err = repo.CheckoutDetachedWorktree("main")
+	if err != nil {
+		t.Fatalf("CheckoutDetachedWorktree() error = %v", err)
+	}
+	if expected := filepath.Join(dir, ".worktrees", "commit-"+head[:7]); path != expected {
+		t.Errorf("commit worktree path = %v, want %v", path, expected)
+	}
+
+	if _, _, err := repo.CheckoutDetachedWorktree("does-not-exist"); err == nil || !strings.Contains(err.Error(), "not a branch, tag or commit") {
+		t.Errorf("CheckoutDetachedWorktree() should reject unknown refs, got: %v", err)
+	}
+}
+
+// Test GetCheckoutRefs lists tags after branches
+func TestGetCheckoutRefs(t *testing.T) {
+	fake := newFakeRunner().
+		on("remote", "origin\n").
+		on(forEachBranchRef, branchRefs([7]string{"refs/heads/main", "", "", "", "", "", ""})).
+		on("tag --list --sort=-creatordate", "v1.0.0\n")
+
+	refs, err := NewRepository("/repo", fake).GetCheckoutRefs()
+	if err != nil {
+		t.Fatalf("GetCheckoutRefs() error = %v", err)
+	}
+	expected := []Branch{{Name: "main"}, {Name: "v1.0.0", IsTag: true}}
+	if !reflect.DeepEqual(refs, expected) {
+		t.Errorf("GetCheckoutRefs() = %v, want %v", refs, expected)
+	}
+}
+
+// Test CheckoutBranchWorktree creates a tracking branch for remote branches
+func TestCheckoutBranchWorktree_Remote(t *testing.T) {
+	withDefaultConfig(t)
+
+	upstream := newTestRepo(t)
+	runGit(t, upstream, "branch", "remote-feature")
+
+	dir := newTestRepo(t)
+	runGit(t, dir, "remote", "add", "origin", upstream)
+	runGit(t, dir, "fetch", "-q", "origin")
+
+	path, created, err := NewRepository(dir, ExecRunner{}).CheckoutBranchWorktree("origin/remote-feature")
+	if err != nil {
+		t.Fatalf("CheckoutBranchWorktree() error = %v", err)
+	}
+	if !created {
+		t.Error("CheckoutBranchWorktree() should report a new worktree")
+	}
+	if upstreamRef := runGit(t, path, "rev-parse", "--abbrev-ref", "@{upstream}"); upstreamRef != "origin/remote-feature" {
+		t.Errorf("worktree upstream = %v, want origin/remote-feature", upstreamRef)
+	}
 }
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+)
+
+// listFormats are the output formats supported by the list command
+var listFormats = []string{"table", "json", "porcelain", "template"}
+
+// HandleListCommand handles the non-interactive list command
+func HandleListCommand(args []string) {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	format := fs.String("format", "table", "output format: table, json, porcelain or template")
+	tmpl := fs.String("template", "", "Go template applied to each worktree (with --format template)")
+	fs.Usage = printListHelp
+
+	positional, err := parseCommandArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return
+		}
+		os.Exit(exitUsage)
+	}
+	if len(positional) != 0 {
+		printListHelp()
+		os.Exit(exitUsage)
+	}
+
+	worktrees, err := currentRepository().ListWorktrees()
+	if err != nil {
+		fail(exitError, "%v", err)
+	}
+
+	if err := writeWorktrees(os.Stdout, worktrees, *format, *tmpl); err != nil {
+		fail(exitError, "%v", err)
+	}
+}
+
+func printListHelp() {
+	fmt.Println("Usage: worktree-util list [--format table|json|porcelain|template] [--template <tmpl>]")
+	fmt.Println("\nOptions:")
+	fmt.Println("  --format <format>    Output format (default: table)")
+	fmt.Println("  --template <tmpl>    Go template for each worktree, e.g. '{{.Path}} {{.Branch}}'")
+	fmt.Println("\nPorcelain output prints one worktree per line with tab-separated fields:")
+	fmt.Println("  <path> <branch> <commit> <main|-> <states|->")
+	fmt.Println("where states is a comma-separated list of bare, locked and prunable.")
+}
+
+// writeWorktrees renders worktrees to w in the requested format
+func writeWorktrees(w io.Writer, worktrees []Worktree, format, tmpl string) error {
+	switch format {
+	case "table":
+		return writeWorktreesTable(w, worktrees)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if worktrees == nil {
+			worktrees = []Worktree{}
+		}
+		return enc.Encode(worktrees)
+	case "porcelain":
+		for _, wt := range worktrees {
+			mainFlag := "-"
+			if wt.IsMain {
+				mainFlag = "main"
+			}
+			states := strings.Join(wt.States(), ",")
+			if states == "" {
+				states = "-"
+			}
+			if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", wt.Path, wt.Branch, wt.Commit, mainFlag, states); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "template":
+		if tmpl == "" {
+			return fmt.Errorf("--format template requires --template")
+		}
+		t, err := template.New("worktree").Parse(tmpl)
+		if err != nil {
+			return fmt.Errorf("invalid template: %w", err)
+		}
+		for _, wt := range worktrees {
+			if err := t.Execute(w, wt); err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown format '%s' (available: %v)", format, listFormats)
+	}
+}
+
+// writeWorktreesTable renders worktrees as an aligned table
+func writeWorktreesTable(w io.Writer, worktrees []Worktree) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "PATH\tBRANCH\tCOMMIT\tMAIN\tSTATE")
+	for _, wt := range worktrees {
+		mainFlag := ""
+		if wt.IsMain {
+			mainFlag = "yes"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%.7s\t%s\t%s\n", wt.Path, wt.Branch, wt.Commit, mainFlag, worktreeStateLabel(wt))
+	}
+	return tw.Flush()
+}
+
+// worktreeStateLabel describes the special states of a worktree with their reasons
+func worktreeStateLabel(wt Worktree) string {
+	var labels []string
+	if wt.IsBare {
+		labels = append(labels, "bare")
+	}
+	if wt.Locked {
+		if wt.LockReason != "" {
+			labels = append(labels, fmt.Sprintf("locked (%s)", wt.LockReason))
+		} else {
+			labels = append(labels, "locked")
+		}
+	}
+	if wt.Prunable {
+		if wt.PrunableReason != "" {
+			labels = append(labels, fmt.Sprintf("prunable (%s)", wt.PrunableReason))
+		} else {
+			labels = append(labels, "prunable")
+		}
+	}
+	return strings.Join(labels, ", ")
+}
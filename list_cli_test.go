@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+var testWorktrees = []Worktree{
+	{Path: "/path/to/repo", Branch: "main", Commit: "abc123def456", IsMain: true},
+	{Path: "/path/to/repo/.worktrees/feature", Branch: "feature", Commit: "def456abc123"},
+}
+
+func TestWriteWorktrees_Table(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeWorktrees(&buf, testWorktrees, "table", ""); err != nil {
+		t.Fatalf("writeWorktrees() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("table output has %d lines, want 3:\n%s", len(lines), buf.String())
+	}
+	if !strings.HasPrefix(lines[0], "PATH") {
+		t.Errorf("table header = %q, should start with PATH", lines[0])
+	}
+	if !strings.Contains(lines[1], "abc123d") || strings.Contains(lines[1], "abc123def456") {
+		t.Errorf("table row should contain short commit, got %q", lines[1])
+	}
+}
+
+func TestWriteWorktrees_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeWorktrees(&buf, testWorktrees, "json", ""); err != nil {
+		t.Fatalf("writeWorktrees() error = %v", err)
+	}
+
+	var decoded []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if len(decoded) != 2 {
+		t.Fatalf("decoded %d worktrees, want 2", len(decoded))
+	}
+	if decoded[0]["path"] != "/path/to/repo" || decoded[0]["is_main"] != true {
+		t.Errorf("unexpected first worktree: %v", decoded[0])
+	}
+
+	// An empty list should still be a JSON array
+	buf.Reset()
+	if err := writeWorktrees(&buf, nil, "json", ""); err != nil {
+		t.Fatalf("writeWorktrees() error = %v", err)
+	}
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("empty JSON output = %q, want []", buf.String())
+	}
+}
+
+func TestWriteWorktrees_Porcelain(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeWorktrees(&buf, testWorktrees, "porcelain", ""); err != nil {
+		t.Fatalf("writeWorktrees() error = %v", err)
+	}
+
+	expected := "/path/to/repo\tmain\tabc123def456\tmain\t-\n" +
+		"/path/to/repo/.worktrees/feature\tfeature\tdef456abc123\t-\t-\n"
+	if buf.String() != expected {
+		t.Errorf("porcelain output = %q, want %q", buf.String(), expected)
+	}
+}
+
+func TestWriteWorktrees_Template(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeWorktrees(&buf, testWorktrees, "template", "{{.Branch}}={{.Path}}"); err != nil {
+		t.Fatalf("writeWorktrees() error = %v", err)
+	}
+
+	expected := "main=/path/to/repo\nfeature=/path/to/repo/.worktrees/feature\n"
+	if buf.String() != expected {
+		t.Errorf("template output = %q, want %q", buf.String(), expected)
+	}
+
+	if err := writeWorktrees(&buf, testWorktrees, "template", ""); err == nil {
+		t.Error("writeWorktrees() should error when template is missing")
+	}
+}
+
+func TestWriteWorktrees_UnknownFormat(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeWorktrees(&buf, testWorktrees, "yaml", "")
+	if err == nil {
+		t.Fatal("writeWorktrees() should error for unknown format")
+	}
+	if !strings.Contains(err.Error(), "unknown format") {
+		t.Errorf("error should mention unknown format, got: %v", err)
+	}
+}
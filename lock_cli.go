@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+// HandleLockCommand locks a worktree so it is not pruned or removed
+func HandleLockCommand(args []string) {
+	fs := flag.NewFlagSet("lock", flag.ContinueOnError)
+	reason := fs.String("reason", "", "why the worktree is locked")
+	fs.Usage = printLockHelp
+
+	positional, err := parseCommandArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return
+		}
+		os.Exit(exitUsage)
+	}
+	if len(positional) != 1 {
+		printLockHelp()
+		os.Exit(exitUsage)
+	}
+
+	repo := currentRepository()
+	wt, err := resolveWorktree(repo, positional[0])
+	if err != nil {
+		fail(exitError, "%v", err)
+	}
+	if err := repo.LockWorktree(wt.Path, *reason); err != nil {
+		fail(exitError, "%v", err)
+	}
+	fmt.Printf("✓ Worktree locked: %s\n", wt.Path)
+}
+
+// HandleUnlockCommand unlocks a locked worktree
+func HandleUnlockCommand(args []string) {
+	fs := flag.NewFlagSet("unlock", flag.ContinueOnError)
+	fs.Usage = printLockHelp
+
+	positional, err := parseCommandArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return
+		}
+		os.Exit(exitUsage)
+	}
+	if len(positional) != 1 {
+		printLockHelp()
+		os.Exit(exitUsage)
+	}
+
+	repo := currentRepository()
+	wt, err := resolveWorktree(repo, positional[0])
+	if err != nil {
+		fail(exitError, "%v", err)
+	}
+	if err := repo.UnlockWorktree(wt.Path); err != nil {
+		fail(exitError, "%v", err)
+	}
+	fmt.Printf("✓ Worktree unlocked: %s\n", wt.Path)
+}
+
+func printLockHelp() {
+	fmt.Println("Usage: worktree-util lock <path|branch> [--reason <text>]")
+	fmt.Println("       worktree-util unlock <path|branch>")
+	fmt.Println("\nLocked worktrees are kept by prune and refused by remove unless --force")
+	fmt.Println("is given. Use this for worktrees on removable drives or long-running")
+	fmt.Println("experiments.")
+}
+
+// resolveWorktree looks up a worktree of repo by path or branch name
+func resolveWorktree(repo *Repository, target string) (Worktree, error) {
+	worktrees, err := repo.ListWorktrees()
+	if err != nil {
+		return Worktree{}, err
+	}
+	wt, err := findWorktree(worktrees, target)
+	if err != nil {
+		return Worktree{}, fmt.Errorf("%s: %v", target, err)
+	}
+	return wt, nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestLockAndUnlockWorktree(t *testing.T) {
+	dir := newTestRepo(t)
+	path := addTestWorktree(t, dir, "experiment")
+	repo := NewRepository(dir, ExecRunner{})
+
+	wt, err := resolveWorktree(repo, "experiment")
+	if err != nil {
+		t.Fatalf("resolveWorktree() error = %v", err)
+	}
+	if wt.Path != path {
+		t.Errorf("resolveWorktree() path = %v, want %v", wt.Path, path)
+	}
+
+	if err := repo.LockWorktree(wt.Path, "long-running experiment"); err != nil {
+		t.Fatalf("LockWorktree() error = %v", err)
+	}
+	wt, _ = resolveWorktree(repo, path)
+	if !wt.Locked || wt.LockReason != "long-running experiment" {
+		t.Errorf("worktree should be locked with reason, got %+v", wt)
+	}
+
+	if err := repo.UnlockWorktree(wt.Path); err != nil {
+		t.Fatalf("UnlockWorktree() error = %v", err)
+	}
+	wt, _ = resolveWorktree(repo, path)
+	if wt.Locked {
+		t.Error("worktree should be unlocked")
+	}
+
+	if err := repo.UnlockWorktree(wt.Path); err == nil {
+		t.Error("UnlockWorktree() should fail for a worktree that is not locked")
+	}
+}
+
+func TestResolveWorktree_NotFound(t *testing.T) {
+	repo := NewRepository(newTestRepo(t), ExecRunner{})
+
+	if _, err := resolveWorktree(repo, "nope"); err == nil {
+		t.Error("resolveWorktree() should fail for unknown worktree")
+	}
+}
@@ -14,8 +14,15 @@ var (
 )
 
 func main() {
+	// Strip global flags shared by all commands
+	args, err := parseGlobalFlags(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitUsage)
+	}
+
 	// Handle version flag
-	if len(os.Args) > 1 && (os.Args[1] == "--version" || os.Args[1] == "-v") {
+	if len(args) > 0 && (args[0] == "--version" || args[0] == "-v") {
 		fmt.Printf("worktree-util version %s\n", version)
 		fmt.Printf("commit: %s\n", commit)
 		fmt.Printf("built at: %s\n", date)
@@ -23,13 +30,13 @@ func main() {
 	}
 
 	// Handle config command
-	if len(os.Args) > 1 && os.Args[1] == "config" {
-		HandleConfigCommand(os.Args[2:])
+	if len(args) > 0 && args[0] == "config" {
+		HandleConfigCommand(args[1:])
 		os.Exit(0)
 	}
 
 	// Handle help flag
-	if len(os.Args) > 1 && (os.Args[1] == "--help" || os.Args[1] == "-h" || os.Args[1] == "help") {
+	if len(args) > 0 && (args[0] == "--help" || args[0] == "-h" || args[0] == "help") {
 		printHelp()
 		os.Exit(0)
 	}
@@ -37,15 +44,61 @@ func main() {
 	// Load configuration
 	config, err := LoadConfig()
 	if err != nil {
-		fmt.Printf("Warning: Failed to load config: %v\n", err)
-		fmt.Println("Using default configuration")
+		// stdout carries paths and completions, keep it clean
+		fmt.Fprintf(os.Stderr, "Warning: Failed to load config: %v\n", err)
+		fmt.Fprintln(os.Stderr, "Using default configuration")
 	}
 
 	// Set global config
 	appConfig = config
 
+	// Handle non-interactive subcommands
+	if len(args) > 0 {
+		switch args[0] {
+		case "list", "ls":
+			HandleListCommand(args[1:])
+			os.Exit(0)
+		case "add":
+			HandleAddCommand(args[1:])
+			os.Exit(0)
+		case "checkout", "co":
+			HandleCheckoutCommand(args[1:])
+			os.Exit(0)
+		case "pr":
+			HandlePullRequestCommand(args[1:])
+			os.Exit(0)
+		case "remove", "rm":
+			HandleRemoveCommand(args[1:])
+			os.Exit(0)
+		case "prune":
+			HandlePruneCommand(args[1:])
+			os.Exit(0)
+		case "lock":
+			HandleLockCommand(args[1:])
+			os.Exit(0)
+		case "unlock":
+			HandleUnlockCommand(args[1:])
+			os.Exit(0)
+		case "move", "mv":
+			HandleMoveCommand(args[1:])
+			os.Exit(0)
+		case "repair":
+			HandleRepairCommand(args[1:])
+			os.Exit(0)
+		case "trash":
+			HandleTrashCommand(args[1:])
+			os.Exit(0)
+		case "shell-init":
+			HandleShellInitCommand(args[1:])
+			os.Exit(0)
+		case "__complete":
+			HandleCompleteCommand(args[1:])
+			os.Exit(0)
+		}
+	}
+
 	// Start TUI
-	p := tea.NewProgram(initialModel(), tea.WithAltScreen())
+	p := tea.NewProgram(initialModel(currentRepository()), tea.WithAltScreen())
 
 	finalModel, err := p.Run()
 	if err != nil {
@@ -53,10 +106,9 @@ func main() {
 		os.Exit(1)
 	}
 
-	// If user selected a worktree to cd into, write path to temp file
+	// If user selected a worktree to cd into, hand it to the shell wrapper
 	if m, ok := finalModel.(model); ok && m.cdPath != "" {
-		tmpFile := "/tmp/worktree-util-cd"
-		if err := os.WriteFile(tmpFile, []byte(m.cdPath), 0644); err != nil {
+		if err := writeCdPath(m.cdPath); err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to write cd path: %v\n", err)
 		}
 	}
@@ -67,9 +119,50 @@ func printHelp() {
 	fmt.Println("A TUI for managing Git worktrees")
 	fmt.Println("\nUsage:")
 	fmt.Println("  worktree-util              Start the TUI")
+	fmt.Println("  worktree-util list         List worktrees without starting the TUI")
+	fmt.Println("  worktree-util add <branch> Create a new branch and worktree")
+	fmt.Println("  worktree-util checkout <branch>")
+	fmt.Println("                             Create or reuse a worktree for a branch, tag or commit")
+	fmt.Println("  worktree-util pr <number>  Check out a pull request into a worktree")
+	fmt.Println("  worktree-util remove <path|branch>...")
+	fmt.Println("                             Remove worktrees")
+	fmt.Println("  worktree-util prune        Remove worktrees whose directories are gone")
+	fmt.Println("  worktree-util lock <path|branch> [--reason <text>]")
+	fmt.Println("                             Protect a worktree from prune and remove")
+	fmt.Println("  worktree-util unlock <path|branch>")
+	fmt.Println("                             Unlock a locked worktree")
+	fmt.Println("  worktree-util move <path|branch> <new-path|--to-branch-name>")
+	fmt.Println("                             Move a worktree, optionally renaming its branch")
+	fmt.Println("  worktree-util repair       Fix worktree links after the repository was moved")
+	fmt.Println("  worktree-util trash [list|restore|purge]")
+	fmt.Println("                             Restore or purge worktrees removed with --trash")
+	fmt.Println("  worktree-util shell-init bash|zsh|fish")
+	fmt.Println("                             Print the shell wrapper and completions")
 	fmt.Println("  worktree-util config       Manage configuration")
 	fmt.Println("  worktree-util --version    Show version information")
 	fmt.Println("  worktree-util --help       Show this help message")
+	fmt.Println("\nGlobal options:")
+	fmt.Println("  --cd-file <file>                  Write the directory to change to into <file>")
+	fmt.Println("                                    (used by the shell wrapper, see wt.sh)")
+	fmt.Println("\nList options:")
+	fmt.Println("  worktree-util list --format table|json|porcelain|template")
+	fmt.Println("                                    Choose the output format (default: table)")
+	fmt.Println("  worktree-util list --format template --template '{{.Path}}'")
+	fmt.Println("                                    Render each worktree with a Go template")
+	fmt.Println("\nAdd options:")
+	fmt.Println("  worktree-util add <branch> [--base <ref>] [--path <dir>] [--no-copy] [--cd]")
+	fmt.Println("                                    Create <branch> (from <ref>) in a new worktree")
+	fmt.Println("\nCheckout options:")
+	fmt.Println("  worktree-util checkout <branch|remote/branch|tag|commit> [--detach] [--cd]")
+	fmt.Println("                                    Print the worktree path for <branch>; tags and")
+	fmt.Println("                                    commits get a detached worktree")
+	fmt.Println("\nPull request options:")
+	fmt.Println("  worktree-util pr <number> [--remote <name>] [--force] [--cd]")
+	fmt.Println("                                    Fetch refs/pull/<number>/head into pr/<number>")
+	fmt.Println("\nRemove options:")
+	fmt.Println("  worktree-util remove <path|branch>... [--force] [--trash] [--delete-branch]")
+	fmt.Println("                                    [--delete-remote-branch] [--dry-run] [--yes]")
+	fmt.Println("                                    Remove worktrees after reporting unsaved work")
 	fmt.Println("\nConfig commands:")
 	fmt.Println("  worktree-util config              Show current configuration")
 	fmt.Println("  worktree-util config init         Create default config file")
@@ -1,12 +1,17 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -18,25 +23,98 @@ const (
 	modeAdd
 	modeCheckout
 	modeConfirmDelete
+	modeConfirmPrune
+	modeLock
+	modeMove
+	modeConfirmRepair
+	modePullRequest
+	modeChooseRemote
+	modeTrash
+	modeBatchCommand
+	modeBatchConfirm
+	modeBatchProgress
 )
 
 type model struct {
-	list         list.Model
-	branchList   list.Model
-	mode         mode
-	pathInput    textinput.Model
-	branchInput  textinput.Model
-	inputFocus   int
-	err          error
-	message      string
-	selectedItem Worktree
-	width        int
-	height       int
-	cdPath       string // Path to cd to when exiting
+	repo          *Repository
+	list          list.Model
+	branchList    list.Model
+	mode          mode
+	pathInput     textinput.Model
+	branchInput   textinput.Model
+	baseInput     textinput.Model
+	reasonInput   textinput.Model
+	moveInput     textinput.Model
+	prInput       textinput.Model
+	prRemoteInput textinput.Model
+	inputFocus    int
+	err           error
+	message       string
+	selectedItem  Worktree
+	deleteChanges WorktreeChanges // Work that would be lost by deleting selectedItem
+	deleteStashes []string        // Stash entries made on the branch of selectedItem
+	confirmForce  bool            // Force removal was chosen and awaits confirmation
+	deleteBranch  bool            // Also delete the local branch of selectedItem
+	forceBranch   bool            // Delete the local branch even though it is unmerged
+	deleteRemote  bool            // Also delete the upstream branch on its remote
+	confirmBranch bool            // Deleting an unmerged branch awaits confirmation
+	confirmRemote bool            // Deleting an unmerged remote branch awaits confirmation
+	branchMerge   BranchMergeState
+	upstreamName  string // Remote and branch the selected branch tracks, if any
+	upstreamRef   string
+	pruneItems    []Worktree // Stale worktrees awaiting prune confirmation
+	repairItems   []BrokenWorktree
+	remoteChoices []string // Remote branches offered when a checkout is ambiguous
+	remoteCursor  int
+	trashItems    []TrashEntry // Trashed worktrees shown by the trash screen
+	trashCursor   int
+	confirmPurge  bool            // Purging the selected trash entry awaits confirmation
+	marked        map[string]bool // Paths of worktrees marked for a batch action
+	batchAction   batchAction
+	batchTargets  []Worktree
+	batchSkipped  []string // Marked worktrees the batch action does not apply to
+	batchResults  []batchResult
+	batchCommand  string
+	commandInput  textinput.Model
+	spinner       spinner.Model
+	fetching      bool                      // A background git fetch is running
+	lastFetched   time.Time                 // Zero until the first fetch finished
+	sortByName    bool                      // Sort the branch picker by name instead of recency
+	showRawRefs   bool                      // List local and remote branches separately
+	branches      []Branch                  // Branches and tags as loaded, before grouping
+	statuses      map[string]WorktreeStatus // Last known status per worktree path
+	preview       viewport.Model
+	previewPath   string            // Worktree shown (or being loaded) in the preview
+	previews      map[string]string // Loaded previews per worktree path
+	width         int
+	height        int
+	cdPath        string // Path to cd to when exiting
 }
 
 type worktreesLoadedMsg []Worktree
 type branchesLoadedMsg []Branch
+
+// baseRefsLoadedMsg carries the default base and the completion candidates
+// for the base field of the add view
+type baseRefsLoadedMsg struct {
+	defaultBase string
+	refs        []string
+}
+type statusesLoadedMsg map[string]WorktreeStatus
+
+// prRemotesLoadedMsg carries the remotes for the pull request view and the
+// one to fetch from by default
+type prRemotesLoadedMsg struct {
+	defaultRemote string
+	remotes       []string
+}
+
+// previewLoadedMsg carries the preview pane content for a worktree
+type previewLoadedMsg struct {
+	path    string
+	content string
+}
+type fetchDoneMsg time.Time
 type errMsg error
 
 var (
@@ -59,8 +137,17 @@ var (
 			Foreground(lipgloss.Color("108")).
 			Bold(true).
 			MarginLeft(2)
+
+	previewStyle = lipgloss.NewStyle().
+			BorderStyle(lipgloss.NormalBorder()).
+			BorderLeft(true).
+			BorderForeground(lipgloss.Color("240")).
+			PaddingLeft(1)
 )
 
+// previewMinWidth is the terminal width below which the preview pane is hidden
+const previewMinWidth = 100
+
 // substringFilter implements case-insensitive substring matching for list filtering
 // This replaces the default fuzzy filter with a more predictable substring search
 func substringFilter(term string, targets []string) []list.Rank {
@@ -109,7 +196,7 @@ func substringFilter(term string, targets []string) []list.Rank {
 	return ranks
 }
 
-func initialModel() model {
+func initialModel(repo *Repository) model {
 	// Create text input for branch name
 	branchInput := textinput.New()
 	branchInput.Placeholder = "feature/my-feature"
@@ -117,6 +204,13 @@ func initialModel() model {
 	branchInput.CharLimit = 256
 	branchInput.Width = 50
 
+	// Create text input for the ref a new branch starts from
+	baseInput := textinput.New()
+	baseInput.Placeholder = "HEAD"
+	baseInput.CharLimit = 256
+	baseInput.Width = 50
+	baseInput.ShowSuggestions = true
+
 	// Create read-only path display (will be auto-generated)
 	pathInput := textinput.New()
 	pathInput.Placeholder = "(auto-generated)"
@@ -124,8 +218,39 @@ func initialModel() model {
 	pathInput.Width = 50
 	pathInput.Blur() // Always blurred since it's read-only
 
-	// Create list
-	delegate := list.NewDefaultDelegate()
+	// Create text input for the lock reason
+	reasonInput := textinput.New()
+	reasonInput.Placeholder = "(optional) e.g. on removable drive"
+	reasonInput.CharLimit = 256
+	reasonInput.Width = 50
+
+	// Create text input for the branch name when moving a worktree
+	moveInput := textinput.New()
+	moveInput.CharLimit = 256
+	moveInput.Width = 50
+
+	// Create text input for the pull request number
+	prInput := textinput.New()
+	prInput.Placeholder = "123"
+	prInput.CharLimit = 16
+	prInput.Width = 50
+
+	// Create text input for the remote the pull request is fetched from
+	prRemoteInput := textinput.New()
+	prRemoteInput.Placeholder = "origin"
+	prRemoteInput.CharLimit = 256
+	prRemoteInput.Width = 50
+	prRemoteInput.ShowSuggestions = true
+
+	// Create text input for the command run in marked worktrees
+	commandInput := textinput.New()
+	commandInput.Placeholder = "git pull --ff-only"
+	commandInput.CharLimit = 256
+	commandInput.Width = 50
+
+	// Create list, the delegate shares the marks with the model
+	marked := make(map[string]bool)
+	delegate := markDelegate{DefaultDelegate: list.NewDefaultDelegate(), marked: marked}
 	l := list.New([]list.Item{}, delegate, 0, 0)
 	l.Title = "Git Worktrees"
 	l.SetShowStatusBar(false)
@@ -134,7 +259,6 @@ func initialModel() model {
 
 	// Create branch list
 	branchDelegate := list.NewDefaultDelegate()
-	branchDelegate.ShowDescription = false // Single-line items
 	bl := list.New([]list.Item{}, branchDelegate, 0, 0)
 	bl.Title = "Select Branch"
 	bl.SetShowStatusBar(false)
@@ -143,33 +267,139 @@ func initialModel() model {
 	bl.Styles.Title = titleStyle
 
 	return model{
-		list:        l,
-		branchList:  bl,
-		mode:        modeList,
-		pathInput:   pathInput,
-		branchInput: branchInput,
-		inputFocus:  0,
+		preview:       viewport.New(0, 0),
+		previews:      make(map[string]string),
+		spinner:       spinner.New(spinner.WithSpinner(spinner.Dot)),
+		repo:          repo,
+		list:          l,
+		branchList:    bl,
+		mode:          modeList,
+		pathInput:     pathInput,
+		branchInput:   branchInput,
+		baseInput:     baseInput,
+		reasonInput:   reasonInput,
+		moveInput:     moveInput,
+		prInput:       prInput,
+		prRemoteInput: prRemoteInput,
+		inputFocus:    0,
+		marked:        marked,
+		commandInput:  commandInput,
 	}
 }
 
 func (m model) Init() tea.Cmd {
-	return loadWorktrees
+	return loadWorktrees(m.repo)
 }
 
-func loadWorktrees() tea.Msg {
-	worktrees, err := ListWorktrees()
-	if err != nil {
-		return errMsg(err)
+func loadWorktrees(repo *Repository) tea.Cmd {
+	return func() tea.Msg {
+		worktrees, err := repo.ListWorktrees()
+		if err != nil {
+			return errMsg(err)
+		}
+		return worktreesLoadedMsg(worktrees)
 	}
-	return worktreesLoadedMsg(worktrees)
 }
 
-func loadBranches() tea.Msg {
-	branches, err := GetAllBranches()
-	if err != nil {
-		return errMsg(err)
+// loadPreview builds the preview pane content for a worktree in the background
+func loadPreview(repo *Repository, wt Worktree) tea.Cmd {
+	return func() tea.Msg {
+		content, err := repo.GetWorktreePreview(wt)
+		if err != nil {
+			content = fmt.Sprintf("⚠ %v", err)
+		}
+		return previewLoadedMsg{path: wt.Path, content: content}
+	}
+}
+
+// loadStatuses gets the git status of all worktrees in the background
+func loadStatuses(repo *Repository, worktrees []Worktree) tea.Cmd {
+	return func() tea.Msg {
+		return statusesLoadedMsg(repo.GetWorktreeStatuses(worktrees))
+	}
+}
+
+func loadBranches(repo *Repository) tea.Cmd {
+	return func() tea.Msg {
+		branches, err := repo.GetCheckoutRefs()
+		if err != nil {
+			return errMsg(err)
+		}
+		return branchesLoadedMsg(branches)
+	}
+}
+
+func loadBaseRefs(repo *Repository) tea.Cmd {
+	return func() tea.Msg {
+		refs, err := repo.BaseRefs()
+		if err != nil {
+			return errMsg(err)
+		}
+		return baseRefsLoadedMsg{defaultBase: defaultBase(repo), refs: refs}
+	}
+}
+
+func loadPullRequestRemotes(repo *Repository) tea.Cmd {
+	return func() tea.Msg {
+		remotes, err := repo.GetRemotes()
+		if err != nil {
+			return errMsg(err)
+		}
+		return prRemotesLoadedMsg{defaultRemote: pullRequestRemote(remotes), remotes: remotes}
+	}
+}
+
+// setWorktrees fills the worktree list, attaching the cached status of each
+func (m *model) setWorktrees(worktrees []Worktree) {
+	items := make([]list.Item, len(worktrees))
+	for i, wt := range worktrees {
+		wt.Status = nil
+		if status, ok := m.statuses[wt.Path]; ok {
+			wt.Status = &status
+		}
+		items[i] = wt
+	}
+	m.list.SetItems(items)
+}
+
+// fetchRemotes runs git fetch in the background and reports when it finished
+func fetchRemotes(repo *Repository) tea.Cmd {
+	return func() tea.Msg {
+		var remotes []string
+		if appConfig != nil {
+			remotes = appConfig.FetchRemotes
+		}
+		if err := repo.FetchRemotes(remotes); err != nil {
+			return errMsg(err)
+		}
+		return fetchDoneMsg(time.Now())
+	}
+}
+
+// startFetch starts a background fetch unless one is already running
+func (m model) startFetch() (model, tea.Cmd) {
+	if m.fetching {
+		return m, nil
+	}
+	m.fetching = true
+	return m, tea.Batch(fetchRemotes(m.repo), m.spinner.Tick)
+}
+
+// checkoutTitle is the header of the checkout picker, with the fetch state
+func (m model) checkoutTitle() string {
+	title := "Select Branch"
+	if m.showRawRefs {
+		title += " (all refs)"
+	}
+	if m.sortByName {
+		title += " (by name)"
+	}
+	if m.fetching {
+		title += " " + m.spinner.View() + "fetching"
+	} else if !m.lastFetched.IsZero() {
+		title += " • fetched " + m.lastFetched.Format("15:04:05")
 	}
-	return branchesLoadedMsg(branches)
+	return title
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -177,30 +407,96 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-		m.list.SetSize(msg.Width, msg.Height-6)
+		if m.previewVisible() {
+			// Split the list view: worktrees left, preview right
+			listWidth := msg.Width / 2
+			m.list.SetSize(listWidth, msg.Height-6)
+			m.preview.Width = msg.Width - listWidth - previewStyle.GetHorizontalFrameSize()
+			m.preview.Height = msg.Height - 6
+		} else {
+			m.list.SetSize(msg.Width, msg.Height-6)
+		}
 		m.branchList.SetSize(msg.Width, msg.Height-6)
-		return m, nil
+		return m.syncPreview()
 
 	case worktreesLoadedMsg:
-		items := make([]list.Item, len(msg))
-		for i, wt := range msg {
-			items[i] = wt
-		}
-		m.list.SetItems(items)
+		// Show the cached status until the fresh one arrives
+		m.setWorktrees(msg)
 		m.err = nil // Clear any previous errors on successful load
+
+		// Worktrees may have changed, reload the preview
+		m.previews = make(map[string]string)
+		m.previewPath = ""
+		var preview tea.Cmd
+		m, preview = m.syncPreview()
+		return m, tea.Batch(loadStatuses(m.repo, msg), preview)
+
+	case previewLoadedMsg:
+		m.previews[msg.path] = msg.content
+		if msg.path == m.previewPath {
+			m.preview.SetContent(msg.content)
+			m.preview.GotoTop()
+		}
 		return m, nil
 
-	case branchesLoadedMsg:
-		items := make([]list.Item, len(msg))
-		for i, br := range msg {
-			items[i] = br
+	case statusesLoadedMsg:
+		m.statuses = msg
+		worktrees := make([]Worktree, 0, len(m.list.Items()))
+		for _, item := range m.list.Items() {
+			worktrees = append(worktrees, item.(Worktree))
 		}
-		m.branchList.SetItems(items)
+		m.setWorktrees(worktrees)
+		return m, nil
+
+	case branchesLoadedMsg:
+		m.branches = msg
+		m.branchList.SetItems(m.branchItems())
 		m.err = nil
 		return m, nil
 
+	case baseRefsLoadedMsg:
+		m.baseInput.SetSuggestions(msg.refs)
+		// Don't overwrite a base the user already started typing
+		if m.baseInput.Value() == "" {
+			m.baseInput.SetValue(msg.defaultBase)
+			m.baseInput.CursorEnd()
+		}
+		return m, nil
+
+	case prRemotesLoadedMsg:
+		m.prRemoteInput.SetSuggestions(msg.remotes)
+		if m.prRemoteInput.Value() == "" {
+			m.prRemoteInput.SetValue(msg.defaultRemote)
+			m.prRemoteInput.CursorEnd()
+		}
+		return m, nil
+
+	case fetchDoneMsg:
+		m.fetching = false
+		m.lastFetched = time.Time(msg)
+		return m, loadBranches(m.repo)
+
+	case batchDoneMsg:
+		m.batchResults = append(m.batchResults, batchResult(msg))
+		if m.batchRunning() {
+			return m, nil
+		}
+		// Marks of removed worktrees would linger, start over
+		clear(m.marked)
+		m.updateListTitle()
+		return m, loadWorktrees(m.repo)
+
+	case spinner.TickMsg:
+		if !m.fetching && !m.batchRunning() {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
 	case errMsg:
 		m.err = msg
+		m.fetching = false
 		return m, nil
 
 	case tea.KeyMsg:
@@ -218,6 +514,26 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateCheckout(msg)
 		case modeConfirmDelete:
 			return m.updateConfirmDelete(msg)
+		case modeConfirmPrune:
+			return m.updateConfirmPrune(msg)
+		case modeLock:
+			return m.updateLock(msg)
+		case modeMove:
+			return m.updateMove(msg)
+		case modeConfirmRepair:
+			return m.updateConfirmRepair(msg)
+		case modePullRequest:
+			return m.updatePullRequest(msg)
+		case modeChooseRemote:
+			return m.updateChooseRemote(msg)
+		case modeTrash:
+			return m.updateTrash(msg)
+		case modeBatchCommand:
+			return m.updateBatchCommand(msg)
+		case modeBatchConfirm:
+			return m.updateBatchConfirm(msg)
+		case modeBatchProgress:
+			return m.updateBatchProgress(msg)
 		}
 	}
 
@@ -267,14 +583,19 @@ func (m model) View() string {
 			b.WriteString("\n\n")
 			b.WriteString(helpStyle.Render("a: add new • c: checkout existing • r: refresh • q: quit"))
 		} else {
-			b.WriteString(m.list.View())
+			if m.previewVisible() {
+				b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, m.list.View(), previewStyle.Render(m.preview.View())))
+			} else {
+				b.WriteString(m.list.View())
+			}
 			b.WriteString("\n")
-			b.WriteString(helpStyle.Render("enter: cd to worktree • a: add new • c: checkout existing • d: delete • r: refresh • q: quit"))
+			b.WriteString(helpStyle.Render("enter: cd to worktree • a: add new • c: checkout existing • P: pull request • d: delete • m: move/rename • l/u: lock/unlock • p: prune • R: repair • T: trash • r: refresh • space: mark • !: run in marked • ctrl+d/u: scroll preview • q: quit"))
 		}
 	case modeAdd:
 		b.WriteString(titleStyle.Render("Add New Worktree"))
 		b.WriteString("\n\n")
 		b.WriteString("  Branch: " + m.branchInput.View() + "\n")
+		b.WriteString("  Base:   " + m.baseInput.View() + "\n")
 
 		// Show auto-generated path preview
 		pathPreview := m.pathInput.Value()
@@ -282,16 +603,17 @@ func (m model) View() string {
 			pathPreview = "(will be auto-generated in .worktrees/)"
 		}
 		b.WriteString(fmt.Sprintf("  Path:   %s\n\n", pathPreview))
-		b.WriteString(helpStyle.Render("enter: create • esc: cancel"))
+		b.WriteString(helpStyle.Render("tab: next field/complete • shift+tab: previous field • enter: create • esc: cancel"))
 	case modeCheckout:
-		if m.err != nil {
-			b.WriteString(titleStyle.Render("Select Branch"))
+		m.branchList.Title = m.checkoutTitle()
+		if m.err != nil && len(m.branchList.Items()) == 0 {
+			b.WriteString(titleStyle.Render(m.checkoutTitle()))
 			b.WriteString("\n\n")
 			b.WriteString(errorStyle.Render(fmt.Sprintf("  ⚠ %v", m.err)))
 			b.WriteString("\n\n")
-			b.WriteString(helpStyle.Render("esc: back"))
+			b.WriteString(helpStyle.Render("F: fetch again • esc: back"))
 		} else if len(m.branchList.Items()) == 0 {
-			b.WriteString(titleStyle.Render("Select Branch"))
+			b.WriteString(titleStyle.Render(m.checkoutTitle()))
 			b.WriteString("\n\n")
 			b.WriteString(helpStyle.Render("  Loading branches..."))
 			b.WriteString("\n\n")
@@ -299,12 +621,122 @@ func (m model) View() string {
 		} else {
 			b.WriteString(m.branchList.View())
 			b.WriteString("\n")
-			b.WriteString(helpStyle.Render("enter: checkout • /: filter • esc: cancel"))
+			b.WriteString(helpStyle.Render("enter: checkout • /: filter • s: sort • t: toggle remotes • F: fetch • esc: cancel"))
 		}
 	case modeConfirmDelete:
 		b.WriteString(titleStyle.Render("Confirm Delete"))
 		b.WriteString("\n\n")
 		b.WriteString(fmt.Sprintf("  Delete worktree: %s?\n\n", m.selectedItem.Path))
+		b.WriteString(m.pendingWorkView())
+		b.WriteString(m.branchCleanupView())
+		switch {
+		case m.confirmRemote:
+			b.WriteString(errorStyle.Render(fmt.Sprintf("%s/%s is not merged into %s, its commits may be lost. Delete it anyway?", m.upstreamName, m.upstreamRef, m.branchMerge.Default)))
+			b.WriteString("\n")
+			b.WriteString(helpStyle.Render("y: delete remote branch • n: keep"))
+		case m.confirmBranch:
+			b.WriteString(errorStyle.Render(fmt.Sprintf("%s is not merged, its commits may be lost. Delete it anyway?", m.selectedItem.Branch)))
+			b.WriteString("\n")
+			b.WriteString(helpStyle.Render("y: delete with -D • n: keep"))
+		case m.confirmForce:
+			b.WriteString(errorStyle.Render("Force removal discards these changes for good. Are you sure?"))
+			b.WriteString("\n")
+			b.WriteString(helpStyle.Render("y: force remove • n: back"))
+		case len(m.deleteChanges.Uncommitted) > 0:
+			b.WriteString(helpStyle.Render("t: move to trash • s: stash then remove • f: force remove • n: cancel"))
+		default:
+			b.WriteString(helpStyle.Render("y: yes • t: move to trash • f: force remove • n: no"))
+		}
+	case modeMove:
+		b.WriteString(titleStyle.Render("Move Worktree"))
+		b.WriteString("\n\n")
+		b.WriteString(fmt.Sprintf("  Worktree: %s\n", m.selectedItem.Path))
+		b.WriteString("  Branch:   " + m.moveInput.View() + "\n")
+		b.WriteString(fmt.Sprintf("  New path: %s\n\n", m.pathInput.Value()))
+		b.WriteString(helpStyle.Render("enter: rename branch and move • esc: cancel"))
+	case modeChooseRemote:
+		b.WriteString(titleStyle.Render("Choose Remote"))
+		b.WriteString("\n\n")
+		b.WriteString("  The branch exists on several remotes, track:\n")
+		for i, choice := range m.remoteChoices {
+			cursor := " "
+			if i == m.remoteCursor {
+				cursor = ">"
+			}
+			b.WriteString(fmt.Sprintf("  %s %s\n", cursor, choice))
+		}
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render("↑/↓: select • enter: checkout • esc: back"))
+	case modeBatchCommand:
+		b.WriteString(titleStyle.Render("Run Command"))
+		b.WriteString("\n\n")
+		b.WriteString("  Command: " + m.commandInput.View() + "\n")
+		b.WriteString(fmt.Sprintf("  Runs with sh in %d marked worktree(s)\n\n", len(m.marked)))
+		b.WriteString(helpStyle.Render("enter: continue • esc: cancel"))
+	case modeBatchConfirm, modeBatchProgress:
+		b.WriteString(m.batchView())
+	case modeTrash:
+		b.WriteString(titleStyle.Render("Trash"))
+		b.WriteString("\n\n")
+		if len(m.trashItems) == 0 {
+			b.WriteString("  Trash is empty\n\n")
+			b.WriteString(helpStyle.Render("esc: back"))
+			break
+		}
+		now := time.Now()
+		for i, entry := range m.trashItems {
+			cursor := " "
+			if i == m.trashCursor {
+				cursor = ">"
+			}
+			changes := ""
+			if entry.HasChanges {
+				changes = " • changes saved"
+			}
+			b.WriteString(fmt.Sprintf("  %s %s\n", cursor, entry.ID))
+			b.WriteString(fmt.Sprintf("      %s • removed %s%s\n", entry.Path, relativeTime(entry.Date, now), changes))
+		}
+		b.WriteString("\n")
+		if m.confirmPurge {
+			b.WriteString(errorStyle.Render(fmt.Sprintf("Purge %s for good?", m.trashItems[m.trashCursor].ID)))
+			b.WriteString("\n")
+			b.WriteString(helpStyle.Render("y: purge • n: keep"))
+		} else {
+			b.WriteString(helpStyle.Render("↑/↓: select • enter: restore • x: purge • esc: back"))
+		}
+	case modePullRequest:
+		b.WriteString(titleStyle.Render("Check Out Pull Request"))
+		b.WriteString("\n\n")
+		b.WriteString("  Number: " + m.prInput.View() + "\n")
+		b.WriteString("  Remote: " + m.prRemoteInput.View() + "\n\n")
+		b.WriteString(helpStyle.Render("tab: next field/complete • shift+tab: previous field • enter: fetch and create worktree • esc: cancel"))
+	case modeLock:
+		b.WriteString(titleStyle.Render("Lock Worktree"))
+		b.WriteString("\n\n")
+		b.WriteString(fmt.Sprintf("  Worktree: %s\n", m.selectedItem.Path))
+		b.WriteString("  Reason:   " + m.reasonInput.View() + "\n\n")
+		b.WriteString(helpStyle.Render("enter: lock • esc: cancel"))
+	case modeConfirmRepair:
+		b.WriteString(titleStyle.Render("Confirm Repair"))
+		b.WriteString("\n\n")
+		b.WriteString("  Repair broken worktree links:\n")
+		for _, broken := range m.repairItems {
+			b.WriteString(fmt.Sprintf("    • %s\n", broken.Path))
+			b.WriteString(fmt.Sprintf("      %s: %s\n", broken.Reason, broken.GitDir))
+		}
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render("y: yes • n: no"))
+	case modeConfirmPrune:
+		b.WriteString(titleStyle.Render("Confirm Prune"))
+		b.WriteString("\n\n")
+		b.WriteString("  Prune stale worktrees:\n")
+		for _, wt := range m.pruneItems {
+			b.WriteString(fmt.Sprintf("    • %s\n", wt.Path))
+			if wt.PrunableReason != "" {
+				b.WriteString(fmt.Sprintf("      %s\n", wt.PrunableReason))
+			}
+		}
+		b.WriteString("\n")
 		b.WriteString(helpStyle.Render("y: yes • n: no"))
 	}
 
@@ -339,37 +771,242 @@ func (m model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.pathInput.SetValue("")
 		m.branchInput.SetValue("")
 		m.branchInput.Focus()
+		m.baseInput.SetValue("")
+		m.baseInput.Blur()
 		m.inputFocus = 0
 		m.err = nil
 		m.message = ""
-		return m, nil
+		return m, loadBaseRefs(m.repo)
+	case "P":
+		m.mode = modePullRequest
+		m.prInput.SetValue("")
+		m.prInput.Focus()
+		m.prRemoteInput.SetValue("")
+		m.prRemoteInput.Blur()
+		m.inputFocus = 0
+		m.err = nil
+		m.message = ""
+		return m, loadPullRequestRemotes(m.repo)
 	case "c":
 		m.mode = modeCheckout
 		m.err = nil
 		m.message = ""
-		return m, loadBranches
+		// Show the refs we have right away, fetched ones follow
+		if appConfig != nil && appConfig.FetchOnCheckout {
+			var fetch tea.Cmd
+			m, fetch = m.startFetch()
+			return m, tea.Batch(loadBranches(m.repo), fetch)
+		}
+		return m, loadBranches(m.repo)
+	case " ":
+		if len(m.list.Items()) > 0 {
+			selected := m.list.SelectedItem().(Worktree)
+			if m.marked[selected.Path] {
+				delete(m.marked, selected.Path)
+			} else {
+				m.marked[selected.Path] = true
+			}
+			m.updateListTitle()
+			m.list.CursorDown()
+			return m.syncPreview()
+		}
+		return m, nil
+	case "esc":
+		// Without marks esc keeps quitting through the list key map
+		if len(m.marked) > 0 {
+			clear(m.marked)
+			m.updateListTitle()
+			return m, nil
+		}
+	case "!":
+		if len(m.marked) == 0 {
+			m.err = fmt.Errorf("mark worktrees with space first")
+			return m, nil
+		}
+		m.mode = modeBatchCommand
+		m.commandInput.SetValue("")
+		m.commandInput.Focus()
+		m.err = nil
+		m.message = ""
+		return m, nil
 	case "d":
+		if len(m.marked) > 0 {
+			return m.confirmBatch(batchRemove)
+		}
 		if len(m.list.Items()) > 0 {
 			selected := m.list.SelectedItem().(Worktree)
 			if selected.IsMain {
 				m.err = fmt.Errorf("cannot delete main worktree")
 				return m, nil
 			}
+			if selected.Locked {
+				m.err = fmt.Errorf("%v (run 'worktree-util remove --force' to remove it anyway)", lockedError(selected))
+				return m, nil
+			}
 			m.selectedItem = selected
+			m = m.withPendingWork(selected)
 			m.mode = modeConfirmDelete
 			m.err = nil
 			m.message = ""
 		}
 		return m, nil
+	case "l":
+		if len(m.marked) > 0 {
+			return m.confirmBatch(batchLock)
+		}
+		if len(m.list.Items()) > 0 {
+			selected := m.list.SelectedItem().(Worktree)
+			if selected.IsMain {
+				m.err = fmt.Errorf("cannot lock main worktree")
+				return m, nil
+			}
+			if selected.Locked {
+				m.err = lockedError(selected)
+				return m, nil
+			}
+			m.selectedItem = selected
+			m.mode = modeLock
+			m.reasonInput.SetValue("")
+			m.reasonInput.Focus()
+			m.err = nil
+			m.message = ""
+		}
+		return m, nil
+	case "m":
+		if len(m.list.Items()) > 0 {
+			selected := m.list.SelectedItem().(Worktree)
+			if selected.IsMain {
+				m.err = fmt.Errorf("cannot move main worktree")
+				return m, nil
+			}
+			if selected.Locked {
+				m.err = lockedError(selected)
+				return m, nil
+			}
+			if selected.Branch == "" || selected.Branch == "detached" {
+				m.err = fmt.Errorf("detached worktree has no branch name; use 'worktree-util move <path> <new-path>'")
+				return m, nil
+			}
+			if isWithinDir(m.repo.Dir, selected.Path) {
+				m.err = fmt.Errorf("cannot move the worktree you are in; use 'worktree-util move' instead")
+				return m, nil
+			}
+			m.selectedItem = selected
+			m.mode = modeMove
+			m.moveInput.SetValue(selected.Branch)
+			m.moveInput.CursorEnd()
+			m.moveInput.Focus()
+			m.pathInput.SetValue("")
+			if path, err := m.repo.GenerateWorktreePath(selected.Branch); err == nil {
+				m.pathInput.SetValue(path)
+			}
+			m.err = nil
+			m.message = ""
+		}
+		return m, nil
+	case "u":
+		if len(m.list.Items()) > 0 {
+			selected := m.list.SelectedItem().(Worktree)
+			if !selected.Locked {
+				m.err = fmt.Errorf("worktree is not locked")
+				return m, nil
+			}
+			if err := m.repo.UnlockWorktree(selected.Path); err != nil {
+				m.err = err
+				return m, nil
+			}
+			m.message = fmt.Sprintf("Worktree unlocked: %s", selected.Path)
+			m.err = nil
+			return m, loadWorktrees(m.repo)
+		}
+		return m, nil
+	case "p":
+		if len(m.marked) > 0 {
+			return m.confirmBatch(batchPrune)
+		}
+		var worktrees []Worktree
+		for _, item := range m.list.Items() {
+			worktrees = append(worktrees, item.(Worktree))
+		}
+		m.err = nil
+		m.pruneItems = prunableWorktrees(worktrees)
+		if len(m.pruneItems) == 0 {
+			m.message = "No stale worktrees to prune"
+			return m, nil
+		}
+		m.mode = modeConfirmPrune
+		m.message = ""
+		return m, nil
+	case "T":
+		entries, err := m.repo.ListTrash()
+		if err != nil {
+			m.err = err
+			return m, nil
+		}
+		m.trashItems = entries
+		m.trashCursor = 0
+		m.confirmPurge = false
+		m.mode = modeTrash
+		m.err = nil
+		m.message = ""
+		return m, nil
+	case "R":
+		broken, err := m.repo.FindBrokenWorktrees()
+		if err != nil {
+			m.err = err
+			return m, nil
+		}
+		m.err = nil
+		m.repairItems = broken
+		if len(broken) == 0 {
+			m.message = "No broken worktrees found"
+			return m, nil
+		}
+		m.mode = modeConfirmRepair
+		m.message = ""
+		return m, nil
 	case "r":
 		m.err = nil
 		m.message = ""
-		return m, loadWorktrees
+		return m, loadWorktrees(m.repo)
+	case "ctrl+d":
+		m.preview.HalfPageDown()
+		return m, nil
+	case "ctrl+u":
+		m.preview.HalfPageUp()
+		return m, nil
 	}
 
-	var cmd tea.Cmd
+	var cmd, preview tea.Cmd
 	m.list, cmd = m.list.Update(msg)
-	return m, cmd
+	m, preview = m.syncPreview()
+	return m, tea.Batch(cmd, preview)
+}
+
+// previewVisible reports whether the terminal is wide enough for the preview pane
+func (m model) previewVisible() bool {
+	return m.width >= previewMinWidth
+}
+
+// syncPreview shows the preview of the selected worktree, loading it in
+// the background the first time the worktree is selected
+func (m model) syncPreview() (model, tea.Cmd) {
+	if !m.previewVisible() {
+		return m, nil
+	}
+	selected, ok := m.list.SelectedItem().(Worktree)
+	if !ok || selected.Path == m.previewPath {
+		return m, nil
+	}
+
+	m.previewPath = selected.Path
+	if content, ok := m.previews[selected.Path]; ok {
+		m.preview.SetContent(content)
+		m.preview.GotoTop()
+		return m, nil
+	}
+	m.preview.SetContent("Loading...")
+	return m, loadPreview(m.repo, selected)
 }
 
 func (m model) updateAdd(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
@@ -387,14 +1024,15 @@ func (m model) updateAdd(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 
 		// Generate path automatically
-		path, err := GenerateWorktreePath(branch)
+		path, err := m.repo.GenerateWorktreePath(branch)
 		if err != nil {
 			m.err = err
 			return m, nil
 		}
 
-		// Create new branch by default
-		err = AddWorktree(path, branch, true)
+		// Create new branch by default, starting from the chosen base
+		base := strings.TrimSpace(m.baseInput.Value())
+		err = m.repo.AddWorktreeWithOptions(path, branch, true, AddOptions{Base: base})
 		if err != nil {
 			m.err = err
 			return m, nil
@@ -403,17 +1041,36 @@ func (m model) updateAdd(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.mode = modeList
 		m.message = fmt.Sprintf("Worktree created: %s", path)
 		m.err = nil
-		return m, loadWorktrees
+		return m, loadWorktrees(m.repo)
+	case "tab":
+		// On the base field tab accepts the suggestion instead
+		if m.inputFocus == 0 {
+			m.inputFocus = 1
+			m.branchInput.Blur()
+			return m, m.baseInput.Focus()
+		}
+	case "shift+tab":
+		if m.inputFocus == 1 {
+			m.inputFocus = 0
+			m.baseInput.Blur()
+			return m, m.branchInput.Focus()
+		}
+		return m, nil
 	}
 
-	// Update branch input and auto-generate path preview
 	var cmd tea.Cmd
+	if m.inputFocus == 1 {
+		m.baseInput, cmd = m.baseInput.Update(msg)
+		return m, cmd
+	}
+
+	// Update branch input and auto-generate path preview
 	m.branchInput, cmd = m.branchInput.Update(msg)
 
 	// Update path preview based on branch name
 	branch := strings.TrimSpace(m.branchInput.Value())
 	if branch != "" {
-		if path, err := GenerateWorktreePath(branch); err == nil {
+		if path, err := m.repo.GenerateWorktreePath(branch); err == nil {
 			m.pathInput.SetValue(path)
 		}
 	} else {
@@ -440,44 +1097,21 @@ func (m model) updateCheckout(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.mode = modeList
 		m.err = nil
 		return m, nil
+	case "F":
+		m.err = nil
+		return m.startFetch()
+	case "s":
+		m.sortByName = !m.sortByName
+		return m, m.branchList.SetItems(m.branchItems())
+	case "t":
+		m.showRawRefs = !m.showRawRefs
+		return m, m.branchList.SetItems(m.branchItems())
 	case "enter":
 		if len(m.branchList.Items()) == 0 {
 			return m, nil
 		}
 
-		selectedBranch := m.branchList.SelectedItem().(Branch)
-
-		// Check if worktree already exists for this branch
-		existingWorktrees, err := ListWorktrees()
-		if err != nil {
-			m.err = err
-			return m, nil
-		}
-
-		alreadyExists := false
-		for _, wt := range existingWorktrees {
-			if wt.Branch == selectedBranch.Name {
-				alreadyExists = true
-				break
-			}
-		}
-
-		// Create worktree from existing branch (or get existing one)
-		path, err := CreateWorktreeFromBranch(selectedBranch.Name)
-		if err != nil {
-			m.err = err
-			return m, nil
-		}
-
-		m.mode = modeList
-		if alreadyExists {
-			m.message = fmt.Sprintf("Worktree already exists for '%s': %s", selectedBranch.Name, path)
-		} else {
-			m.message = fmt.Sprintf("Worktree created from branch '%s': %s", selectedBranch.Name, path)
-		}
-		m.cdPath = path
-		m.err = nil
-		return m, loadWorktrees
+		return m.checkout(m.branchList.SelectedItem().(Branch))
 	}
 
 	// Update branch list for all other keys
@@ -486,21 +1120,508 @@ func (m model) updateCheckout(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
-func (m model) updateConfirmDelete(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "y":
-		err := RemoveWorktree(m.selectedItem.Path, false)
-		if err != nil {
-			m.err = err
-			m.mode = modeList
-			return m, nil
+// branchItems returns the loaded branches for the picker, grouped by name
+// unless raw refs are shown
+func (m model) branchItems() []list.Item {
+	branches := m.branches
+	if !m.showRawRefs {
+		branches = groupBranches(branches)
+	}
+	return sortBranches(branches, m.sortByName)
+}
+
+// sortBranches orders branches by name or by most recent commit (the
+// default); refs without a commit date, like tags, keep their order at the end
+func sortBranches(branches []Branch, byName bool) []list.Item {
+	sorted := append([]Branch(nil), branches...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if byName {
+			return sorted[i].Name < sorted[j].Name
 		}
+		return sorted[i].CommitDate.After(sorted[j].CommitDate)
+	})
+
+	items := make([]list.Item, len(sorted))
+	for i, branch := range sorted {
+		items[i] = branch
+	}
+	return items
+}
 
+// checkout creates (or reuses) the worktree for a branch picked in the TUI
+func (m model) checkout(selectedBranch Branch) (tea.Model, tea.Cmd) {
+	// Create worktree from existing branch (or get existing one)
+	// Tags are checked out detached
+	checkout, kind := m.repo.CheckoutBranchWorktree, "branch"
+	if selectedBranch.IsTag {
+		checkout, kind = m.repo.CheckoutDetachedWorktree, "tag"
+	}
+	// Branches already checked out are known from the branch list
+	if selectedBranch.WorktreePath != "" {
 		m.mode = modeList
-		m.message = fmt.Sprintf("Worktree removed: %s", m.selectedItem.Path)
+		m.message = fmt.Sprintf("Worktree already exists for '%s': %s", selectedBranch.Name, selectedBranch.WorktreePath)
+		m.cdPath = selectedBranch.WorktreePath
 		m.err = nil
-		return m, loadWorktrees
-	case "n", "esc":
+		return m, nil
+	}
+
+	path, created, err := checkout(selectedBranch.Name)
+	var ambiguous *AmbiguousBranchError
+	if errors.As(err, &ambiguous) {
+		m.mode = modeChooseRemote
+		m.remoteChoices = ambiguous.Candidates
+		m.remoteCursor = 0
+		m.err = nil
+		return m, nil
+	}
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+
+	m.mode = modeList
+	if !created {
+		m.message = fmt.Sprintf("Worktree already exists for '%s': %s", selectedBranch.Name, path)
+	} else {
+		m.message = fmt.Sprintf("Worktree created from %s '%s': %s", kind, selectedBranch.Name, path)
+	}
+	m.cdPath = path
+	m.err = nil
+	return m, loadWorktrees(m.repo)
+}
+
+func (m model) updateChooseRemote(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k":
+		if m.remoteCursor > 0 {
+			m.remoteCursor--
+		}
+	case "down", "j":
+		if m.remoteCursor < len(m.remoteChoices)-1 {
+			m.remoteCursor++
+		}
+	case "enter":
+		if len(m.remoteChoices) == 0 {
+			return m, nil
+		}
+		return m.checkout(Branch{Name: m.remoteChoices[m.remoteCursor], IsRemote: true})
+	case "esc":
+		m.mode = modeCheckout
+		m.remoteChoices = nil
+		m.err = nil
+	}
+
+	return m, nil
+}
+
+func (m model) updateTrash(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.confirmPurge {
+		switch msg.String() {
+		case "y":
+			m.confirmPurge = false
+			entry := m.trashItems[m.trashCursor]
+			if err := m.repo.PurgeTrash(entry.ID); err != nil {
+				m.err = err
+				return m, nil
+			}
+			m.trashItems = append(m.trashItems[:m.trashCursor:m.trashCursor], m.trashItems[m.trashCursor+1:]...)
+			if m.trashCursor >= len(m.trashItems) && m.trashCursor > 0 {
+				m.trashCursor--
+			}
+			m.message = fmt.Sprintf("Purged %s", entry.ID)
+			m.err = nil
+		case "n", "esc":
+			m.confirmPurge = false
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "up", "k":
+		if m.trashCursor > 0 {
+			m.trashCursor--
+		}
+	case "down", "j":
+		if m.trashCursor < len(m.trashItems)-1 {
+			m.trashCursor++
+		}
+	case "enter":
+		if len(m.trashItems) == 0 {
+			return m, nil
+		}
+		entry := m.trashItems[m.trashCursor]
+		if err := m.repo.RestoreTrash(entry); err != nil {
+			m.err = err
+			return m, nil
+		}
+		m.mode = modeList
+		m.message = fmt.Sprintf("Worktree restored: %s", entry.Path)
+		m.err = nil
+		return m, loadWorktrees(m.repo)
+	case "x":
+		if len(m.trashItems) > 0 {
+			m.confirmPurge = true
+			m.message = ""
+		}
+	case "esc":
+		m.mode = modeList
+		m.err = nil
+		m.message = ""
+	}
+
+	return m, nil
+}
+
+// updateListTitle shows how many worktrees are marked
+func (m *model) updateListTitle() {
+	m.list.Title = "Git Worktrees"
+	if n := len(m.marked); n > 0 {
+		m.list.Title += fmt.Sprintf(" (%d marked)", n)
+	}
+}
+
+// markedWorktrees returns the marked worktrees in list order
+func (m model) markedWorktrees() []Worktree {
+	var worktrees []Worktree
+	for _, item := range m.list.Items() {
+		if wt := item.(Worktree); m.marked[wt.Path] {
+			worktrees = append(worktrees, wt)
+		}
+	}
+	return worktrees
+}
+
+// confirmBatch shows the summary of action on the marked worktrees
+func (m model) confirmBatch(action batchAction) (tea.Model, tea.Cmd) {
+	targets, skipped := splitBatchTargets(action, m.markedWorktrees())
+	if len(targets) == 0 {
+		m.mode = modeList
+		m.err = fmt.Errorf("%s does not apply to any marked worktree", strings.ToLower(action.String()))
+		return m, nil
+	}
+
+	m.batchAction = action
+	m.batchTargets = targets
+	m.batchSkipped = skipped
+	m.mode = modeBatchConfirm
+	m.err = nil
+	m.message = ""
+	return m, nil
+}
+
+// startBatch runs the confirmed batch action in the background
+func (m model) startBatch() (tea.Model, tea.Cmd) {
+	m.mode = modeBatchProgress
+	m.batchResults = nil
+	return m, tea.Batch(runBatch(m.repo, m.batchAction, m.batchTargets, m.batchCommand), m.spinner.Tick)
+}
+
+// batchRunning reports whether batch results are still outstanding
+func (m model) batchRunning() bool {
+	return m.mode == modeBatchProgress && len(m.batchResults) < len(m.batchTargets)
+}
+
+func (m model) updateBatchCommand(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.mode = modeList
+		m.commandInput.Blur()
+		m.err = nil
+		return m, nil
+	case tea.KeyEnter:
+		command := strings.TrimSpace(m.commandInput.Value())
+		if command == "" {
+			m.err = fmt.Errorf("command cannot be empty")
+			return m, nil
+		}
+		m.batchCommand = command
+		m.commandInput.Blur()
+		return m.confirmBatch(batchRun)
+	}
+
+	var cmd tea.Cmd
+	m.commandInput, cmd = m.commandInput.Update(msg)
+	return m, cmd
+}
+
+func (m model) updateBatchConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y":
+		return m.startBatch()
+	case "t":
+		if m.batchAction != batchRemove {
+			return m, nil
+		}
+		updated, cmd := m.confirmBatch(batchTrash)
+		if m = updated.(model); m.mode != modeBatchConfirm {
+			return m, cmd
+		}
+		return m.startBatch()
+	case "n", "esc":
+		m.mode = modeList
+		m.err = nil
+	}
+
+	return m, nil
+}
+
+func (m model) updateBatchProgress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.batchRunning() {
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "enter", "esc", "q":
+		failed := 0
+		for _, result := range m.batchResults {
+			if result.Err != nil {
+				failed++
+			}
+		}
+		m.mode = modeList
+		m.message = fmt.Sprintf("%s: %d succeeded, %d failed", m.batchAction, len(m.batchResults)-failed, failed)
+		m.err = nil
+	}
+
+	return m, nil
+}
+
+// batchView renders the batch confirmation and progress screens
+func (m model) batchView() string {
+	var b strings.Builder
+
+	if m.mode == modeBatchConfirm {
+		b.WriteString(titleStyle.Render("Confirm " + m.batchAction.String()))
+		b.WriteString("\n\n")
+		if m.batchAction == batchRun {
+			b.WriteString(fmt.Sprintf("  Command: %s\n\n", m.batchCommand))
+		}
+		b.WriteString(fmt.Sprintf("  %d worktree(s):\n", len(m.batchTargets)))
+		for _, wt := range m.batchTargets {
+			line := "    • " + wt.Path
+			if status, ok := m.statuses[wt.Path]; ok && m.batchAction == batchRemove && status.IsDirty() {
+				line += "  ⚠ " + status.Indicators()
+			}
+			b.WriteString(line + "\n")
+		}
+		if len(m.batchSkipped) > 0 {
+			b.WriteString(fmt.Sprintf("\n  Skipped %d:\n", len(m.batchSkipped)))
+			for _, skipped := range m.batchSkipped {
+				b.WriteString("    • " + skipped + "\n")
+			}
+		}
+		b.WriteString("\n")
+		if m.batchAction == batchRemove {
+			b.WriteString(helpStyle.Render("y: remove • t: move to trash instead • n: cancel"))
+		} else {
+			b.WriteString(helpStyle.Render("y: yes • n: no"))
+		}
+		return b.String()
+	}
+
+	b.WriteString(titleStyle.Render(m.batchAction.String()))
+	b.WriteString("\n\n")
+	if m.batchRunning() {
+		b.WriteString(fmt.Sprintf("  %s %d/%d done\n\n", m.spinner.View(), len(m.batchResults), len(m.batchTargets)))
+	} else {
+		b.WriteString(fmt.Sprintf("  All %d done\n\n", len(m.batchTargets)))
+	}
+	for _, result := range m.batchResults {
+		if result.Err != nil {
+			b.WriteString(fmt.Sprintf("  ✗ %s: %v\n", result.Path, result.Err))
+		} else if result.Output != "" {
+			b.WriteString(fmt.Sprintf("  ✓ %s: %s\n", result.Path, result.Output))
+		} else {
+			b.WriteString(fmt.Sprintf("  ✓ %s\n", result.Path))
+		}
+	}
+	b.WriteString("\n")
+	if m.batchRunning() {
+		b.WriteString(helpStyle.Render("running..."))
+	} else {
+		b.WriteString(helpStyle.Render("enter: back to list"))
+	}
+	return b.String()
+}
+
+// maxPendingLines caps each section of the delete confirmation
+const maxPendingLines = 10
+
+// pendingWorkView lists the uncommitted files, stash entries and unpushed
+// commits of the worktree awaiting deletion
+func (m model) pendingWorkView() string {
+	var b strings.Builder
+	section := func(title string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		b.WriteString(fmt.Sprintf("  ⚠ %s:\n", title))
+		for i, line := range lines {
+			if i == maxPendingLines {
+				b.WriteString(fmt.Sprintf("      ... and %d more\n", len(lines)-i))
+				break
+			}
+			b.WriteString("      " + line + "\n")
+		}
+		b.WriteString("\n")
+	}
+	section(fmt.Sprintf("%d modified or untracked file(s)", len(m.deleteChanges.Uncommitted)), m.deleteChanges.Uncommitted)
+	section(fmt.Sprintf("%d stash entry(ies) on this branch", len(m.deleteStashes)), m.deleteStashes)
+	section(fmt.Sprintf("%d commit(s) not on any remote or other branch", len(m.deleteChanges.Unpushed)), m.deleteChanges.Unpushed)
+	return b.String()
+}
+
+// withPendingWork collects what deleting wt would throw away or leave
+// behind, and preselects the branch cleanup from the config. A missing
+// directory has nothing to lose, so errors count as no changes.
+func (m model) withPendingWork(wt Worktree) model {
+	changes, err := m.repo.GetWorktreeChanges(wt.Path)
+	if err != nil {
+		changes = WorktreeChanges{}
+	}
+	m.deleteChanges = changes
+	m.deleteStashes = nil
+	m.branchMerge = BranchMergeState{}
+	m.upstreamName, m.upstreamRef = "", ""
+	m.confirmForce, m.confirmBranch, m.confirmRemote, m.forceBranch = false, false, false, false
+	m.deleteBranch, m.deleteRemote = false, false
+
+	if !hasBranch(wt) {
+		return m
+	}
+	m.deleteStashes, _ = m.repo.BranchStashes(wt.Branch)
+	m.branchMerge, _ = m.repo.BranchMerged(wt.Branch)
+	m.upstreamName, m.upstreamRef, _ = m.repo.BranchUpstream(wt.Branch)
+	if appConfig != nil {
+		// Unmerged branches are never deleted without asking
+		m.deleteBranch = appConfig.DeleteBranch && m.branchMerge.Merged
+		m.deleteRemote = appConfig.DeleteRemoteBranch && m.upstreamName != "" && m.branchMerge.MergedIntoDefault
+	}
+	return m
+}
+
+// branchCleanupView shows the merge state of the branch and whether it
+// is deleted together with the worktree
+func (m model) branchCleanupView() string {
+	if !hasBranch(m.selectedItem) {
+		return ""
+	}
+	branch := m.selectedItem.Branch
+
+	var b strings.Builder
+	if m.branchMerge.Merged {
+		b.WriteString(fmt.Sprintf("  Branch %s is merged into %s\n", branch, m.branchMerge.Target))
+	} else {
+		b.WriteString(fmt.Sprintf("  ⚠ Branch %s is not merged into %s\n", branch, m.branchMerge.Target))
+	}
+	local := "also delete local branch " + branch
+	if m.forceBranch {
+		local += " (unmerged, -D)"
+	}
+	b.WriteString(fmt.Sprintf("  %s b: %s\n", checkbox(m.deleteBranch), local))
+	if m.upstreamName != "" {
+		remote := fmt.Sprintf("also delete remote branch %s/%s", m.upstreamName, m.upstreamRef)
+		if !m.branchMerge.MergedIntoDefault {
+			remote += fmt.Sprintf(" (not merged into %s)", m.branchMerge.Default)
+		}
+		b.WriteString(fmt.Sprintf("  %s r: %s\n", checkbox(m.deleteRemote), remote))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// checkbox renders an option of a confirmation screen
+func checkbox(checked bool) string {
+	if checked {
+		return "[x]"
+	}
+	return "[ ]"
+}
+
+func (m model) updateConfirmDelete(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	path := m.selectedItem.Path
+
+	if m.confirmRemote {
+		switch msg.String() {
+		case "y":
+			m.deleteRemote = true
+			m.confirmRemote = false
+		case "n", "esc":
+			m.confirmRemote = false
+		}
+		return m, nil
+	}
+
+	if m.confirmBranch {
+		switch msg.String() {
+		case "y":
+			m.deleteBranch, m.forceBranch = true, true
+			m.confirmBranch = false
+		case "n", "esc":
+			m.confirmBranch = false
+		}
+		return m, nil
+	}
+
+	if m.confirmForce {
+		switch msg.String() {
+		case "y":
+			return m.removeSelected(m.repo.RemoveWorktree(path, true), fmt.Sprintf("Worktree force removed: %s", path))
+		case "n", "esc":
+			m.confirmForce = false
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "y":
+		// Uncommitted changes need an explicit choice of what happens to them
+		if len(m.deleteChanges.Uncommitted) > 0 {
+			m.err = fmt.Errorf("the worktree has uncommitted changes: press t to move it to the trash, s to stash them or f to force remove")
+			return m, nil
+		}
+		// On failure the confirmation stays so the user can still force it
+		return m.removeSelected(m.repo.RemoveWorktree(path, false), fmt.Sprintf("Worktree removed: %s", path))
+	case "b":
+		switch {
+		case !hasBranch(m.selectedItem):
+		case m.deleteBranch:
+			m.deleteBranch, m.forceBranch = false, false
+		case m.branchMerge.Merged:
+			m.deleteBranch = true
+		default:
+			m.confirmBranch = true
+		}
+		return m, nil
+	case "r":
+		switch {
+		case m.upstreamName == "":
+		case m.deleteRemote:
+			m.deleteRemote = false
+		case m.branchMerge.MergedIntoDefault:
+			m.deleteRemote = true
+		default:
+			m.confirmRemote = true
+		}
+		return m, nil
+	case "t":
+		id, err := m.repo.RemoveWorktreeWithOptions(path, RemoveOptions{Trash: true})
+		return m.removeSelected(err, fmt.Sprintf("Worktree moved to trash as %s: %s", id, path))
+	case "f":
+		m.confirmForce = true
+		m.err = nil
+		return m, nil
+	case "s":
+		if len(m.deleteChanges.Uncommitted) == 0 {
+			return m, nil
+		}
+		stash, err := m.repo.StashWorktree(path)
+		if err != nil {
+			m.err = err
+			return m, nil
+		}
+		return m.removeSelected(m.repo.RemoveWorktree(path, false), fmt.Sprintf("Changes stashed as %s, worktree removed: %s", stash, path))
+	case "n", "esc":
 		m.mode = modeList
 		m.err = nil
 		return m, nil
@@ -508,3 +1629,226 @@ func (m model) updateConfirmDelete(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	return m, nil
 }
+
+// removeSelected finishes a removal from the delete confirmation and
+// deletes the branches that were selected along with it
+func (m model) removeSelected(err error, message string) (tea.Model, tea.Cmd) {
+	m.confirmForce = false
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+
+	m.mode = modeList
+	m.message = message
+	m.err = nil
+
+	// The upstream was looked up before, deleting the local branch does not
+	// lose it. A failed local deletion keeps the remote branch as well.
+	branch := m.selectedItem.Branch
+	if m.deleteBranch && hasBranch(m.selectedItem) {
+		if err := m.repo.DeleteBranch(branch, m.forceBranch); err != nil {
+			m.err = fmt.Errorf("worktree removed, but the branch was kept: %v", err)
+			return m, loadWorktrees(m.repo)
+		}
+		m.message += fmt.Sprintf(", branch %s deleted", branch)
+	}
+	if m.deleteRemote && m.upstreamName != "" {
+		if err := m.repo.DeleteRemoteBranch(m.upstreamName, m.upstreamRef); err != nil {
+			m.err = fmt.Errorf("worktree removed, but the remote branch was kept: %v", err)
+			return m, loadWorktrees(m.repo)
+		}
+		m.message += fmt.Sprintf(", remote branch %s/%s deleted", m.upstreamName, m.upstreamRef)
+	}
+
+	return m, loadWorktrees(m.repo)
+}
+
+func (m model) updateConfirmPrune(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y":
+		m.mode = modeList
+		if err := m.repo.PruneWorktrees(); err != nil {
+			m.err = err
+			return m, nil
+		}
+
+		m.message = fmt.Sprintf("Pruned %d stale worktree(s)", len(m.pruneItems))
+		m.pruneItems = nil
+		m.err = nil
+		return m, loadWorktrees(m.repo)
+	case "n", "esc":
+		m.mode = modeList
+		m.pruneItems = nil
+		m.err = nil
+		return m, nil
+	}
+
+	return m, nil
+}
+
+func (m model) updateLock(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = modeList
+		m.reasonInput.Blur()
+		m.err = nil
+		return m, nil
+	case "enter":
+		reason := strings.TrimSpace(m.reasonInput.Value())
+		m.mode = modeList
+		m.reasonInput.Blur()
+		if err := m.repo.LockWorktree(m.selectedItem.Path, reason); err != nil {
+			m.err = err
+			return m, nil
+		}
+
+		m.message = fmt.Sprintf("Worktree locked: %s", m.selectedItem.Path)
+		m.err = nil
+		return m, loadWorktrees(m.repo)
+	}
+
+	var cmd tea.Cmd
+	m.reasonInput, cmd = m.reasonInput.Update(msg)
+	return m, cmd
+}
+
+func (m model) updateMove(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = modeList
+		m.moveInput.Blur()
+		m.err = nil
+		return m, nil
+	case "enter":
+		branch := strings.TrimSpace(m.moveInput.Value())
+		if branch == "" {
+			m.err = fmt.Errorf("branch name cannot be empty")
+			return m, nil
+		}
+
+		path, err := m.repo.RenameWorktree(m.selectedItem, branch, "", false)
+		if err != nil {
+			m.err = err
+			return m, nil
+		}
+
+		m.mode = modeList
+		m.moveInput.Blur()
+		if path == m.selectedItem.Path && branch == m.selectedItem.Branch {
+			m.message = fmt.Sprintf("Worktree already in sync: %s", path)
+		} else {
+			m.message = fmt.Sprintf("Worktree moved: %s", path)
+		}
+		m.err = nil
+		return m, loadWorktrees(m.repo)
+	}
+
+	var cmd tea.Cmd
+	m.moveInput, cmd = m.moveInput.Update(msg)
+
+	// Update path preview based on the new branch name
+	branch := strings.TrimSpace(m.moveInput.Value())
+	if branch != "" {
+		if path, err := m.repo.GenerateWorktreePath(branch); err == nil {
+			m.pathInput.SetValue(path)
+		}
+	} else {
+		m.pathInput.SetValue("")
+	}
+
+	return m, cmd
+}
+
+func (m model) updateConfirmRepair(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y":
+		m.mode = modeList
+		if err := m.repo.RepairWorktrees(brokenPaths(m.repairItems)); err != nil {
+			m.err = err
+			return m, nil
+		}
+
+		remaining, err := m.repo.FindBrokenWorktrees()
+		if err != nil {
+			m.err = err
+			return m, nil
+		}
+		if len(remaining) > 0 {
+			m.err = fmt.Errorf("%d worktree(s) are still broken, e.g. %s", len(remaining), remaining[0].Path)
+		} else {
+			m.message = fmt.Sprintf("Repaired %d worktree(s)", len(m.repairItems))
+			m.err = nil
+		}
+		m.repairItems = nil
+		return m, loadWorktrees(m.repo)
+	case "n", "esc":
+		m.mode = modeList
+		m.repairItems = nil
+		m.err = nil
+		return m, nil
+	}
+
+	return m, nil
+}
+
+func (m model) updatePullRequest(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = modeList
+		m.prInput.Blur()
+		m.prRemoteInput.Blur()
+		m.err = nil
+		return m, nil
+	case "enter":
+		number, err := parsePullRequestNumber(m.prInput.Value())
+		if err != nil {
+			m.err = err
+			return m, nil
+		}
+		remote := strings.TrimSpace(m.prRemoteInput.Value())
+		if remote == "" {
+			remote = "origin"
+		}
+
+		path, created, err := m.repo.CheckoutPullRequest(remote, number, false)
+		if err != nil {
+			m.err = err
+			return m, nil
+		}
+
+		m.mode = modeList
+		m.prInput.Blur()
+		m.prRemoteInput.Blur()
+		if !created {
+			m.message = fmt.Sprintf("Worktree already exists for pull request #%d: %s", number, path)
+		} else {
+			m.message = fmt.Sprintf("Worktree created for pull request #%d: %s", number, path)
+		}
+		m.cdPath = path
+		m.err = nil
+		return m, loadWorktrees(m.repo)
+	case "tab":
+		// On the remote field tab accepts the suggestion instead
+		if m.inputFocus == 0 {
+			m.inputFocus = 1
+			m.prInput.Blur()
+			return m, m.prRemoteInput.Focus()
+		}
+	case "shift+tab":
+		if m.inputFocus == 1 {
+			m.inputFocus = 0
+			m.prRemoteInput.Blur()
+			return m, m.prInput.Focus()
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	if m.inputFocus == 1 {
+		m.prRemoteInput, cmd = m.prRemoteInput.Update(msg)
+		return m, cmd
+	}
+	m.prInput, cmd = m.prInput.Update(msg)
+	return m, cmd
+}
@@ -1,7 +1,15 @@
 package main
 
 import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 func TestSubstringFilter(t *testing.T) {
@@ -70,3 +78,547 @@ func TestSubstringFilter(t *testing.T) {
 	}
 }
 
+func TestLoadWorktrees_FakeRepository(t *testing.T) {
+	fake := newFakeRunner().
+		on("rev-parse --show-toplevel", "/repo\n").
+		on("worktree list --porcelain", "worktree /repo\nHEAD abc123\nbranch refs/heads/main\n\n")
+	m := initialModel(NewRepository("/repo", fake))
+
+	msg := m.Init()()
+	loaded, ok := msg.(worktreesLoadedMsg)
+	if !ok {
+		t.Fatalf("Init() returned %T, want worktreesLoadedMsg", msg)
+	}
+	if len(loaded) != 1 || loaded[0].Path != "/repo" {
+		t.Errorf("loaded worktrees = %v, want the main worktree", loaded)
+	}
+
+	updated, _ := m.Update(msg)
+	if items := updated.(model).list.Items(); len(items) != 1 {
+		t.Errorf("list has %d items, want 1", len(items))
+	}
+}
+
+func TestLoadWorktrees_NotARepository(t *testing.T) {
+	fake := newFakeRunner().onError("rev-parse --show-toplevel", "fatal: not a git repository\n")
+
+	msg := loadWorktrees(NewRepository("/tmp", fake))()
+	if _, ok := msg.(errMsg); !ok {
+		t.Errorf("loadWorktrees() returned %T, want errMsg", msg)
+	}
+}
+
+func TestUpdateList_Prune(t *testing.T) {
+	fake := newFakeRunner().
+		on("worktree prune", "").
+		on("rev-parse --show-toplevel", "/repo\n").
+		on("worktree list --porcelain", "worktree /repo\nHEAD abc123\nbranch refs/heads/main\n\n")
+	m := initialModel(NewRepository("/repo", fake))
+	m.list.SetItems([]list.Item{
+		Worktree{Path: "/repo", Branch: "main", IsMain: true},
+		Worktree{Path: "/repo/.worktrees/gone", Branch: "gone", Prunable: true, PrunableReason: "gitdir file points to non-existent location"},
+	})
+
+	updated, _ := m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	m = updated.(model)
+	if m.mode != modeConfirmPrune || len(m.pruneItems) != 1 {
+		t.Fatalf("p should ask to prune 1 worktree, mode = %v, items = %v", m.mode, m.pruneItems)
+	}
+	if !strings.Contains(m.View(), "non-existent location") {
+		t.Error("prune confirmation should show the prunable reason")
+	}
+
+	updated, cmd := m.updateConfirmPrune(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	m = updated.(model)
+	if !fake.called("worktree prune") {
+		t.Error("confirming should run git worktree prune")
+	}
+	if m.mode != modeList || cmd == nil {
+		t.Error("confirming should return to the list and reload worktrees")
+	}
+}
+
+func TestUpdateList_LockWithReason(t *testing.T) {
+	fake := newFakeRunner().on("worktree lock --reason usb drive /repo/.worktrees/x", "")
+	m := initialModel(NewRepository("/repo", fake))
+	m.list.SetItems([]list.Item{Worktree{Path: "/repo/.worktrees/x", Branch: "x"}})
+
+	updated, _ := m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l")})
+	m = updated.(model)
+	if m.mode != modeLock {
+		t.Fatalf("l should open the lock prompt, mode = %v", m.mode)
+	}
+
+	m.reasonInput.SetValue("usb drive")
+	updated, _ = m.updateLock(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if m.err != nil {
+		t.Fatalf("locking failed: %v", m.err)
+	}
+	if !fake.called("worktree lock --reason usb drive /repo/.worktrees/x") {
+		t.Errorf("unexpected git calls: %v", fake.calls)
+	}
+
+	// Locked worktrees cannot be deleted from the TUI
+	m.list.SetItems([]list.Item{Worktree{Path: "/repo/.worktrees/x", Branch: "x", Locked: true, LockReason: "usb drive"}})
+	updated, _ = m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	m = updated.(model)
+	if m.mode == modeConfirmDelete || m.err == nil {
+		t.Error("d on a locked worktree should be refused")
+	}
+}
+
+func TestUpdateAdd_Base(t *testing.T) {
+	withDefaultConfig(t)
+
+	fake := newFakeRunner().
+		on("symbolic-ref --quiet --short refs/remotes/origin/HEAD", "origin/main\n").
+		on("remote", "origin\n").
+		on(forEachBranchRef, branchRefs(
+			[7]string{"refs/heads/main", "1700000000", "", "", "", "", ""},
+			[7]string{"refs/remotes/origin/main", "1700000000", "", "", "", "", ""},
+		)).
+		on("tag --list --sort=-creatordate", "v1.0.0\n").
+		on("rev-parse --show-toplevel", "/repo\n").
+		on("worktree add --no-track -b feature /repo/.worktrees/feature v1.0.0", "")
+	m := initialModel(NewRepository("/repo", fake))
+
+	updated, cmd := m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	m = updated.(model)
+	if m.mode != modeAdd || cmd == nil {
+		t.Fatalf("a should open the add view and load base refs, mode = %v", m.mode)
+	}
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+	if base := m.baseInput.Value(); base != "origin/main" {
+		t.Errorf("base field = %q, want the default branch origin/main", base)
+	}
+
+	m.branchInput.SetValue("feature")
+	updated, _ = m.updateAdd(tea.KeyMsg{Type: tea.KeyTab})
+	m = updated.(model)
+	if m.inputFocus != 1 {
+		t.Fatal("tab should move the focus to the base field")
+	}
+
+	m.baseInput.SetValue("v1.0.0")
+	updated, _ = m.updateAdd(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if m.err != nil {
+		t.Fatalf("creating the worktree failed: %v", m.err)
+	}
+	if !fake.called("worktree add --no-track -b feature /repo/.worktrees/feature v1.0.0") {
+		t.Errorf("unexpected git calls: %v", fake.calls)
+	}
+}
+
+func TestCheckout_ChooseRemote(t *testing.T) {
+	withDefaultConfig(t)
+
+	upstream := newTestRepo(t)
+	runGit(t, upstream, "branch", "shared")
+	dir := newTestRepo(t)
+	runGit(t, dir, "remote", "add", "origin", upstream)
+	runGit(t, dir, "remote", "add", "upstream", upstream)
+	runGit(t, dir, "fetch", "-q", "--all")
+	m := initialModel(NewRepository(dir, ExecRunner{}))
+
+	updated, _ := m.checkout(Branch{Name: "shared"})
+	m = updated.(model)
+	if m.mode != modeChooseRemote || len(m.remoteChoices) != 2 {
+		t.Fatalf("ambiguous checkout should ask for a remote, mode = %v, choices = %v", m.mode, m.remoteChoices)
+	}
+	if !strings.Contains(m.View(), "upstream/shared") {
+		t.Error("remote prompt should list the candidates")
+	}
+
+	updated, _ = m.updateChooseRemote(tea.KeyMsg{Type: tea.KeyDown})
+	m = updated.(model)
+	updated, _ = m.updateChooseRemote(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if m.err != nil {
+		t.Fatalf("checkout failed: %v", m.err)
+	}
+	if m.mode != modeList {
+		t.Errorf("choosing a remote should return to the list, mode = %v", m.mode)
+	}
+	if upstreamRef := runGit(t, m.cdPath, "rev-parse", "--abbrev-ref", "@{upstream}"); upstreamRef != "upstream/shared" {
+		t.Errorf("worktree upstream = %v, want upstream/shared", upstreamRef)
+	}
+}
+
+func TestUpdateCheckout_Fetch(t *testing.T) {
+	withConfig(t, &Config{WorktreeDir: ".worktrees", FetchRemotes: []string{"origin"}})
+
+	fake := newFakeRunner().on("fetch --prune --multiple origin", "")
+	m := initialModel(NewRepository("/repo", fake))
+	m.mode = modeCheckout
+
+	updated, cmd := m.updateCheckout(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("F")})
+	m = updated.(model)
+	if !m.fetching || cmd == nil {
+		t.Fatal("F should start a background fetch")
+	}
+	if !strings.Contains(m.View(), "fetching") {
+		t.Error("the header should show that a fetch is running")
+	}
+
+	// A second F while fetching does not start another fetch
+	if _, cmd := m.updateCheckout(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("F")}); cmd != nil {
+		t.Error("F should not start a second fetch")
+	}
+
+	msg := fetchRemotes(m.repo)()
+	if _, ok := msg.(fetchDoneMsg); !ok {
+		t.Fatalf("fetchRemotes() returned %T, want fetchDoneMsg", msg)
+	}
+	updated, cmd = m.Update(msg)
+	m = updated.(model)
+	if m.fetching || m.lastFetched.IsZero() || cmd == nil {
+		t.Error("a finished fetch should record the time and reload branches")
+	}
+	if !strings.Contains(m.checkoutTitle(), "fetched") {
+		t.Errorf("checkoutTitle() = %q, want the last fetch time", m.checkoutTitle())
+	}
+}
+
+func TestUpdateCheckout_FetchError(t *testing.T) {
+	withDefaultConfig(t)
+
+	fake := newFakeRunner().onError("fetch --prune --all", "fatal: unable to access remote\n")
+	m := initialModel(NewRepository("/repo", fake))
+	m.mode = modeCheckout
+	m.fetching = true
+
+	msg := fetchRemotes(m.repo)()
+	if _, ok := msg.(errMsg); !ok {
+		t.Fatalf("fetchRemotes() returned %T, want errMsg", msg)
+	}
+	updated, _ := m.Update(msg)
+	m = updated.(model)
+	if m.fetching || m.err == nil {
+		t.Error("a failed fetch should stop the spinner and show the error")
+	}
+}
+
+func TestSortBranches(t *testing.T) {
+	branches := []Branch{
+		{Name: "alpha-old", CommitDate: time.Unix(100, 0)},
+		{Name: "v1.0.0", IsTag: true},
+		{Name: "zeta-new", CommitDate: time.Unix(300, 0)},
+	}
+
+	names := func(items []list.Item) []string {
+		var result []string
+		for _, item := range items {
+			result = append(result, item.(Branch).Name)
+		}
+		return result
+	}
+
+	if result := names(sortBranches(branches, false)); !reflect.DeepEqual(result, []string{"zeta-new", "alpha-old", "v1.0.0"}) {
+		t.Errorf("sortBranches() by recency = %v", result)
+	}
+	if result := names(sortBranches(branches, true)); !reflect.DeepEqual(result, []string{"alpha-old", "v1.0.0", "zeta-new"}) {
+		t.Errorf("sortBranches() by name = %v", result)
+	}
+}
+
+func TestCheckout_ExistingWorktree(t *testing.T) {
+	// No git calls are scripted: the branch list already knows the worktree
+	fake := newFakeRunner()
+	m := initialModel(NewRepository("/repo", fake))
+
+	updated, _ := m.checkout(Branch{Name: "feature", WorktreePath: "/repo/.worktrees/feature"})
+	m = updated.(model)
+	if m.err != nil || m.cdPath != "/repo/.worktrees/feature" {
+		t.Errorf("checkout should reuse the known worktree, cdPath = %q, err = %v", m.cdPath, m.err)
+	}
+	if len(fake.calls) != 0 {
+		t.Errorf("checkout should not run git, calls = %v", fake.calls)
+	}
+}
+
+func TestUpdateCheckout_ToggleRawRefs(t *testing.T) {
+	m := initialModel(NewRepository("/repo", newFakeRunner()))
+	m.mode = modeCheckout
+
+	updated, _ := m.Update(branchesLoadedMsg{
+		{Name: "feature"},
+		{Name: "origin/feature", IsRemote: true, Remote: "origin"},
+		{Name: "upstream/feature", IsRemote: true, Remote: "upstream"},
+	})
+	m = updated.(model)
+	if items := m.branchList.Items(); len(items) != 1 {
+		t.Fatalf("grouped view has %d items, want 1", len(items))
+	}
+
+	updated, _ = m.updateCheckout(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	m = updated.(model)
+	if items := m.branchList.Items(); len(items) != 3 {
+		t.Errorf("raw view has %d items, want 3", len(items))
+	}
+	if !strings.Contains(m.checkoutTitle(), "all refs") {
+		t.Errorf("checkoutTitle() = %q, want the raw refs marker", m.checkoutTitle())
+	}
+}
+
+func TestWorktreeStatuses_Refresh(t *testing.T) {
+	m := initialModel(NewRepository("/repo", newFakeRunner()))
+	worktrees := []Worktree{{Path: "/repo", Branch: "main", IsMain: true}}
+
+	updated, cmd := m.Update(worktreesLoadedMsg(worktrees))
+	m = updated.(model)
+	if cmd == nil {
+		t.Fatal("loading worktrees should start loading their status")
+	}
+
+	updated, _ = m.Update(statusesLoadedMsg{"/repo": {Changed: 1, Behind: 2}})
+	m = updated.(model)
+	wt := m.list.Items()[0].(Worktree)
+	if !strings.Contains(wt.Description(), "● 1 changed ↓2") {
+		t.Errorf("Description() = %q, want the status indicators", wt.Description())
+	}
+
+	// A reload keeps showing the cached status until the new one arrives
+	updated, _ = m.Update(worktreesLoadedMsg(worktrees))
+	m = updated.(model)
+	if wt := m.list.Items()[0].(Worktree); wt.Status == nil {
+		t.Error("reloaded worktrees should keep the cached status")
+	}
+}
+
+func TestPreview_LoadsLazily(t *testing.T) {
+	m := initialModel(NewRepository("/repo", newFakeRunner()))
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(model)
+
+	updated, cmd := m.Update(worktreesLoadedMsg{
+		{Path: "/repo", Branch: "main", IsMain: true},
+		{Path: "/repo/.worktrees/feature", Branch: "feature"},
+	})
+	m = updated.(model)
+	if m.previewPath != "/repo" || cmd == nil {
+		t.Fatalf("the preview of the selected worktree should load, previewPath = %q", m.previewPath)
+	}
+
+	updated, _ = m.Update(previewLoadedMsg{path: "/repo", content: "main preview"})
+	m = updated.(model)
+	if !strings.Contains(m.View(), "main preview") {
+		t.Error("the loaded preview should be shown next to the list")
+	}
+
+	updated, cmd = m.updateList(tea.KeyMsg{Type: tea.KeyDown})
+	m = updated.(model)
+	if m.previewPath != "/repo/.worktrees/feature" || cmd == nil {
+		t.Fatalf("moving the cursor should load the next preview, previewPath = %q", m.previewPath)
+	}
+
+	// A late result for another worktree is cached but not shown
+	updated, _ = m.Update(previewLoadedMsg{path: "/repo", content: "stale preview"})
+	m = updated.(model)
+	if strings.Contains(m.View(), "stale preview") {
+		t.Error("a preview for another worktree should not replace the current one")
+	}
+
+	updated, _ = m.updateList(tea.KeyMsg{Type: tea.KeyUp})
+	m = updated.(model)
+	if !strings.Contains(m.View(), "stale preview") {
+		t.Error("going back should show the cached preview")
+	}
+}
+
+// newDirtyWorktree creates a worktree on branch feature with a modified and
+// an untracked file
+func newDirtyWorktree(t *testing.T) (*Repository, Worktree) {
+	t.Helper()
+
+	dir := newTestRepo(t)
+	runGit(t, dir, "config", "user.name", "Test")
+	runGit(t, dir, "config", "user.email", "test@example.com")
+	path := addTestWorktree(t, dir, "feature")
+	if err := os.WriteFile(filepath.Join(path, "README.md"), []byte("changed\n"), 0644); err != nil {
+		t.Fatalf("Failed to modify file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(path, "notes.txt"), []byte("todo\n"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	return NewRepository(dir, ExecRunner{}), Worktree{Path: path, Branch: "feature"}
+}
+
+func TestConfirmDelete_ForceNeedsSecondConfirmation(t *testing.T) {
+	repo, wt := newDirtyWorktree(t)
+	m := initialModel(repo)
+	m.list.SetItems([]list.Item{wt})
+
+	updated, _ := m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	m = updated.(model)
+	view := m.View()
+	for _, want := range []string{"2 modified or untracked file(s)", "?? notes.txt", "f: force remove"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("delete confirmation should contain %q, got:\n%s", want, view)
+		}
+	}
+
+	// y is not enough with uncommitted changes
+	updated, _ = m.updateConfirmDelete(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	m = updated.(model)
+	if m.err == nil || !strings.Contains(m.View(), "press t to move it to the trash") {
+		t.Errorf("y with uncommitted changes should explain the choices, got:\n%s", m.View())
+	}
+	updated, _ = m.updateConfirmDelete(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	m = updated.(model)
+	if _, err := os.Stat(wt.Path); err != nil || m.mode != modeConfirmDelete || !m.confirmForce {
+		t.Fatalf("f should ask again before removing, mode = %v, err = %v", m.mode, err)
+	}
+
+	updated, _ = m.updateConfirmDelete(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	m = updated.(model)
+	if m.err != nil {
+		t.Fatalf("force removal failed: %v", m.err)
+	}
+	if _, err := os.Stat(wt.Path); !os.IsNotExist(err) || m.mode != modeList {
+		t.Errorf("confirming force should remove the worktree, mode = %v", m.mode)
+	}
+}
+
+func TestConfirmDelete_StashThenRemove(t *testing.T) {
+	repo, wt := newDirtyWorktree(t)
+	m := initialModel(repo)
+	m.list.SetItems([]list.Item{wt})
+
+	updated, _ := m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	m = updated.(model)
+	updated, _ = m.updateConfirmDelete(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	m = updated.(model)
+	if m.err != nil {
+		t.Fatalf("stash then remove failed: %v", m.err)
+	}
+	if _, err := os.Stat(wt.Path); !os.IsNotExist(err) {
+		t.Error("the worktree should be removed after stashing")
+	}
+
+	stashes, err := repo.BranchStashes("feature")
+	if err != nil || len(stashes) != 1 {
+		t.Fatalf("BranchStashes() = %v, %v, want one stash", stashes, err)
+	}
+	if files := runGit(t, repo.Dir, "show", "--name-only", "--format=", "stash@{0}^3"); files != "notes.txt" {
+		t.Errorf("the stash should keep untracked files, got %q", files)
+	}
+}
+
+func TestConfirmDelete_UnmergedBranch(t *testing.T) {
+	withConfig(t, &Config{WorktreeDir: ".worktrees", DeleteBranch: true})
+
+	repo, path := newRemoveTestRepo(t)
+	runGit(t, path, "commit", "-q", "--allow-empty", "-m", "local work")
+	m := initialModel(repo)
+	m.list.SetItems([]list.Item{Worktree{Path: path, Branch: "feature"}})
+
+	updated, _ := m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	m = updated.(model)
+	if m.deleteBranch {
+		t.Error("delete_branch should not preselect an unmerged branch")
+	}
+	if !strings.Contains(m.View(), "Branch feature is not merged into main") {
+		t.Errorf("the merge state should be shown up front, got:\n%s", m.View())
+	}
+
+	updated, _ = m.updateConfirmDelete(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	m = updated.(model)
+	if !m.confirmBranch || m.deleteBranch {
+		t.Fatal("b on an unmerged branch should ask for confirmation first")
+	}
+	updated, _ = m.updateConfirmDelete(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	m = updated.(model)
+	if !m.deleteBranch || !m.forceBranch {
+		t.Fatal("confirming should select deleting the branch with -D")
+	}
+
+	updated, _ = m.updateConfirmDelete(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	m = updated.(model)
+	if m.err != nil {
+		t.Fatalf("removal failed: %v", m.err)
+	}
+	if branches := runGit(t, repo.Dir, "branch", "--list", "feature"); branches != "" {
+		t.Errorf("branch should be deleted, got %q", branches)
+	}
+	if !strings.Contains(m.message, "branch feature deleted") {
+		t.Errorf("message = %q, should report the deleted branch", m.message)
+	}
+}
+
+func TestTrash_RemoveAndRestore(t *testing.T) {
+	repo, wt := newDirtyWorktree(t)
+	m := initialModel(repo)
+	m.list.SetItems([]list.Item{wt})
+
+	updated, _ := m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	m = updated.(model)
+	updated, _ = m.updateConfirmDelete(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	m = updated.(model)
+	if m.err != nil {
+		t.Fatalf("moving to trash failed: %v", m.err)
+	}
+	if _, err := os.Stat(wt.Path); !os.IsNotExist(err) || !strings.Contains(m.message, "moved to trash") {
+		t.Errorf("t should remove the worktree into the trash, message = %q", m.message)
+	}
+
+	updated, _ = m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("T")})
+	m = updated.(model)
+	if m.mode != modeTrash || len(m.trashItems) != 1 {
+		t.Fatalf("T should open the trash with one entry, mode = %v, items = %v", m.mode, m.trashItems)
+	}
+	if !strings.Contains(m.View(), "changes saved") {
+		t.Errorf("the trash should show saved changes, got:\n%s", m.View())
+	}
+
+	updated, cmd := m.updateTrash(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if m.err != nil {
+		t.Fatalf("restore failed: %v", m.err)
+	}
+	if _, err := os.Stat(filepath.Join(wt.Path, "notes.txt")); err != nil || m.mode != modeList || cmd == nil {
+		t.Errorf("enter should restore the worktree and reload the list, mode = %v", m.mode)
+	}
+}
+
+func TestConfirmDelete_CleanWorktreeWithoutRemotes(t *testing.T) {
+	repo, path := newRemoveTestRepo(t)
+	m := initialModel(repo)
+	m.list.SetItems([]list.Item{Worktree{Path: path, Branch: "feature"}})
+
+	updated, _ := m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	m = updated.(model)
+	if view := m.View(); strings.Contains(view, "⚠ ") && !strings.Contains(view, "⚠ Branch") {
+		t.Errorf("a clean worktree without remotes should not warn about lost work, got:\n%s", view)
+	}
+	if m.deleteChanges.HasChanges() {
+		t.Errorf("deleteChanges = %+v, want none", m.deleteChanges)
+	}
+}
+
+func TestPullRequest_ChooseRemote(t *testing.T) {
+	withDefaultConfig(t)
+
+	fake := newFakeRunner().on("remote", "origin\nupstream\n")
+	m := initialModel(NewRepository("/repo", fake))
+
+	updated, cmd := m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("P")})
+	m = updated.(model)
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+	if remote := m.prRemoteInput.Value(); remote != "upstream" {
+		t.Errorf("remote = %q, want upstream in a fork", remote)
+	}
+
+	updated, _ = m.updatePullRequest(tea.KeyMsg{Type: tea.KeyTab})
+	m = updated.(model)
+	if m.inputFocus != 1 || !m.prRemoteInput.Focused() || m.prInput.Focused() {
+		t.Error("tab should move the focus to the remote")
+	}
+	if !strings.Contains(m.View(), "Remote: ") || strings.Contains(m.View(), "Remote: origin") {
+		t.Errorf("the view should show the chosen remote:\n%s", m.View())
+	}
+}
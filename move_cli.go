@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HandleMoveCommand moves a worktree and optionally renames its branch
+func HandleMoveCommand(args []string) {
+	fs := flag.NewFlagSet("move", flag.ContinueOnError)
+	toBranchName := fs.Bool("to-branch-name", false, "move to the path generated from the branch name")
+	renameBranch := fs.String("rename-branch", "", "rename the worktree's branch")
+	force := fs.Bool("force", false, "also move locked worktrees")
+	fs.Usage = printMoveHelp
+
+	positional, err := parseCommandArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return
+		}
+		os.Exit(exitUsage)
+	}
+
+	// Either an explicit new path or a generated one, never both
+	var newPath string
+	switch {
+	case len(positional) == 2 && !*toBranchName:
+		newPath, err = filepath.Abs(positional[1])
+		if err != nil {
+			fail(exitError, "%v", err)
+		}
+	case len(positional) == 1 && (*toBranchName || *renameBranch != ""):
+		// Path is generated from the (renamed) branch
+	default:
+		printMoveHelp()
+		os.Exit(exitUsage)
+	}
+
+	repo := currentRepository()
+	wt, err := resolveWorktree(repo, positional[0])
+	if err != nil {
+		fail(exitError, "%v", err)
+	}
+
+	movedPath, err := repo.RenameWorktree(wt, *renameBranch, newPath, *force)
+	if err != nil {
+		fail(exitError, "%v", err)
+	}
+
+	if *renameBranch != "" && *renameBranch != wt.Branch {
+		fmt.Fprintf(os.Stderr, "✓ Branch renamed: %s → %s\n", wt.Branch, *renameBranch)
+	}
+	if movedPath == wt.Path {
+		fmt.Fprintf(os.Stderr, "✓ Worktree already at %s\n", movedPath)
+	} else {
+		fmt.Fprintf(os.Stderr, "✓ Worktree moved: %s → %s\n", wt.Path, movedPath)
+	}
+	fmt.Println(movedPath)
+
+	// Follow the worktree if the shell was inside it
+	if cwd, err := os.Getwd(); err == nil && isWithinDir(cwd, wt.Path) && movedPath != wt.Path {
+		rel, _ := filepath.Rel(wt.Path, cwd)
+		if err := writeCdPath(filepath.Join(movedPath, rel)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: the current directory was moved to %s\n", movedPath)
+		}
+	}
+}
+
+func printMoveHelp() {
+	fmt.Println("Usage: worktree-util move <path|branch> <new-path> [--rename-branch <name>] [--force]")
+	fmt.Println("       worktree-util move <path|branch> --to-branch-name [--rename-branch <name>] [--force]")
+	fmt.Println("\nMoves a worktree with git worktree move. With --to-branch-name the new path")
+	fmt.Println("is generated from the branch name and the configured worktree_dir, which")
+	fmt.Println("brings the directory back in sync after a branch rename. --rename-branch")
+	fmt.Println("renames the branch first and implies --to-branch-name unless a path is given.")
+	fmt.Println("\nOptions:")
+	fmt.Println("  --to-branch-name       Move to the path generated from the branch name")
+	fmt.Println("  --rename-branch <name> Rename the worktree's branch to <name>")
+	fmt.Println("  --force                Also move locked worktrees")
+}
+
+// isWithinDir reports whether path is dir or inside dir
+func isWithinDir(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newMoveTestRepo creates a repo with a worktree for branch "feature/old"
+func newMoveTestRepo(t *testing.T) (*Repository, Worktree) {
+	t.Helper()
+
+	withDefaultConfig(t)
+
+	dir := newTestRepo(t)
+	addTestWorktree(t, dir, "feature/old")
+	repo := NewRepository(dir, ExecRunner{})
+
+	wt, err := resolveWorktree(repo, "feature/old")
+	if err != nil {
+		t.Fatalf("resolveWorktree() error = %v", err)
+	}
+	return repo, wt
+}
+
+func TestRenameWorktree_RenameBranch(t *testing.T) {
+	repo, wt := newMoveTestRepo(t)
+
+	path, err := repo.RenameWorktree(wt, "feature/new", "", false)
+	if err != nil {
+		t.Fatalf("RenameWorktree() error = %v", err)
+	}
+
+	expected := filepath.Join(repo.Dir, ".worktrees", "feature-new")
+	if path != expected {
+		t.Errorf("RenameWorktree() path = %v, want %v", path, expected)
+	}
+	if _, err := os.Stat(wt.Path); !os.IsNotExist(err) {
+		t.Error("old worktree directory should be gone")
+	}
+	if branch := runGit(t, path, "rev-parse", "--abbrev-ref", "HEAD"); branch != "feature/new" {
+		t.Errorf("worktree branch = %v, want feature/new", branch)
+	}
+}
+
+func TestRenameWorktree_SyncAfterExternalRename(t *testing.T) {
+	repo, wt := newMoveTestRepo(t)
+	runGit(t, wt.Path, "branch", "-m", "feature/renamed")
+
+	wt, err := resolveWorktree(repo, wt.Path)
+	if err != nil {
+		t.Fatalf("resolveWorktree() error = %v", err)
+	}
+
+	path, err := repo.RenameWorktree(wt, "", "", false)
+	if err != nil {
+		t.Fatalf("RenameWorktree() error = %v", err)
+	}
+	if expected := filepath.Join(repo.Dir, ".worktrees", "feature-renamed"); path != expected {
+		t.Errorf("RenameWorktree() path = %v, want %v", path, expected)
+	}
+
+	// Running it again is a no-op
+	wt, _ = resolveWorktree(repo, path)
+	again, err := repo.RenameWorktree(wt, "", "", false)
+	if err != nil || again != path {
+		t.Errorf("RenameWorktree() on synced worktree = %v, %v; want %v, nil", again, err, path)
+	}
+}
+
+func TestRenameWorktree_ExplicitPath(t *testing.T) {
+	repo, wt := newMoveTestRepo(t)
+	target := filepath.Join(t.TempDir(), "elsewhere")
+
+	path, err := repo.RenameWorktree(wt, "", target, false)
+	if err != nil {
+		t.Fatalf("RenameWorktree() error = %v", err)
+	}
+	if path != target {
+		t.Errorf("RenameWorktree() path = %v, want %v", path, target)
+	}
+	if _, err := os.Stat(filepath.Join(target, "README.md")); err != nil {
+		t.Errorf("worktree should be at the new path: %v", err)
+	}
+}
+
+func TestRenameWorktree_Refused(t *testing.T) {
+	repo, wt := newMoveTestRepo(t)
+
+	mainWorktree := Worktree{Path: repo.Dir, Branch: "main", IsMain: true}
+	if _, err := repo.RenameWorktree(mainWorktree, "other", "", false); err == nil {
+		t.Error("RenameWorktree() should refuse the main worktree")
+	}
+
+	wt.Locked = true
+	if _, err := repo.RenameWorktree(wt, "feature/new", "", false); err == nil {
+		t.Error("RenameWorktree() should refuse locked worktrees without force")
+	}
+
+	wt.Locked = false
+	blocker := filepath.Join(repo.Dir, ".worktrees", "feature-taken")
+	if err := os.MkdirAll(blocker, 0755); err != nil {
+		t.Fatalf("Failed to create blocking directory: %v", err)
+	}
+	if _, err := repo.RenameWorktree(wt, "feature/taken", "", false); err == nil {
+		t.Fatal("RenameWorktree() should fail when the destination exists")
+	}
+
+	// A failed move must not leave the branch renamed
+	if _, err := repo.RenameWorktree(wt, "feature/other", "/dev/null/impossible", false); err == nil {
+		t.Fatal("RenameWorktree() should fail when git cannot move the worktree")
+	}
+	if branch := runGit(t, wt.Path, "rev-parse", "--abbrev-ref", "HEAD"); branch != "feature/old" {
+		t.Errorf("branch should be restored after a failed move, got %v", branch)
+	}
+}
+
+func TestRenameWorktree_RollbackFails(t *testing.T) {
+	fake := newFakeRunner().
+		on("branch -m feature/old feature/new", "").
+		onError("worktree move /repo/.worktrees/feature-old /elsewhere/feature-new", "fatal: cannot move\n").
+		onError("branch -m feature/new feature/old", "fatal: cannot lock ref\n")
+	repo := NewRepository("/repo", fake)
+	wt := Worktree{Path: "/repo/.worktrees/feature-old", Branch: "feature/old"}
+
+	_, err := repo.RenameWorktree(wt, "feature/new", "/elsewhere/feature-new", false)
+	if err == nil || !strings.Contains(err.Error(), "cannot move") || !strings.Contains(err.Error(), "still called feature/new") {
+		t.Errorf("RenameWorktree() error = %v, want both the move and the rollback failure", err)
+	}
+}
+
+func TestIsWithinDir(t *testing.T) {
+	tests := []struct {
+		path, dir string
+		expected  bool
+	}{
+		{"/repo/.worktrees/a", "/repo/.worktrees/a", true},
+		{"/repo/.worktrees/a/src", "/repo/.worktrees/a", true},
+		{"/repo/.worktrees/ab", "/repo/.worktrees/a", false},
+		{"/repo", "/repo/.worktrees/a", false},
+	}
+
+	for _, tt := range tests {
+		if result := isWithinDir(tt.path, tt.dir); result != tt.expected {
+			t.Errorf("isWithinDir(%q, %q) = %v, want %v", tt.path, tt.dir, result, tt.expected)
+		}
+	}
+}
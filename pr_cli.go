@@ -0,0 +1,107 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HandlePullRequestCommand checks out a GitHub pull request or GitLab merge
+// request into a worktree without the TUI
+func HandlePullRequestCommand(args []string) {
+	fs := flag.NewFlagSet("pr", flag.ContinueOnError)
+	remote := fs.String("remote", "origin", "remote to fetch the pull request from")
+	force := fs.Bool("force", false, "overwrite an existing pr/<number> branch that cannot be fast-forwarded")
+	cd := fs.Bool("cd", false, "change the shell to the worktree (requires the shell wrapper)")
+	fs.Usage = printPullRequestHelp
+
+	positional, err := parseCommandArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return
+		}
+		os.Exit(exitUsage)
+	}
+	if len(positional) != 1 {
+		printPullRequestHelp()
+		os.Exit(exitUsage)
+	}
+
+	number, err := parsePullRequestNumber(positional[0])
+	if err != nil {
+		fail(exitUsage, "%v", err)
+	}
+
+	path, created, err := currentRepository().CheckoutPullRequest(*remote, number, *force)
+	if err != nil {
+		fail(exitError, "%v", err)
+	}
+
+	if created {
+		fmt.Fprintf(os.Stderr, "✓ Worktree created for pull request #%d: %s\n", number, path)
+	} else {
+		fmt.Fprintf(os.Stderr, "✓ Worktree already exists for pull request #%d: %s\n", number, path)
+		fmt.Fprintln(os.Stderr, "  Run 'git pull' in the worktree to fetch new commits")
+	}
+	fmt.Println(path)
+
+	if *cd {
+		if err := writeCdPath(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to write cd path: %v\n", err)
+		}
+	}
+}
+
+func printPullRequestHelp() {
+	fmt.Println("Usage: worktree-util pr <number> [--remote <name>] [--force] [--cd]")
+	fmt.Println("\nFetches a pull request into the local branch pr/<number> and creates a")
+	fmt.Println("worktree for it. GitLab merge requests are fetched when the remote is")
+	fmt.Println("configured with 'pull_request_refs: {<remote>: gitlab}'.")
+	fmt.Println("The worktree path is printed on stdout.")
+	fmt.Println("\nOptions:")
+	fmt.Println("  --remote <name>    Fetch from <name> instead of origin")
+	fmt.Println("  --force            Overwrite an existing pr/<number> branch, e.g. after a rebase;")
+	fmt.Println("                     otherwise it is only fast-forwarded")
+	fmt.Println("  --cd               Change to the worktree (requires the shell wrapper)")
+}
+
+// parsePullRequestNumber accepts "123" and "#123"
+func parsePullRequestNumber(value string) (int, error) {
+	number, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(value), "#"))
+	if err != nil || number <= 0 {
+		return 0, fmt.Errorf("invalid pull request number: %s", value)
+	}
+	return number, nil
+}
+
+// pullRequestRemote picks the remote pull requests are fetched from when none
+// is given: a preferred remote, one configured in pull_request_refs, then
+// upstream (in a fork, pull requests live there) and origin
+func pullRequestRemote(remotes []string) string {
+	var candidates []string
+	if appConfig != nil {
+		candidates = append(candidates, appConfig.PreferredRemotes...)
+		configured := make([]string, 0, len(appConfig.PullRequestRefs))
+		for remote := range appConfig.PullRequestRefs {
+			configured = append(configured, remote)
+		}
+		sort.Strings(configured)
+		candidates = append(candidates, configured...)
+	}
+	candidates = append(candidates, "upstream", "origin")
+
+	for _, candidate := range candidates {
+		for _, remote := range remotes {
+			if remote == candidate {
+				return remote
+			}
+		}
+	}
+	if len(remotes) > 0 {
+		return remotes[0]
+	}
+	return "origin"
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newPullRequestRemote creates a bare remote that publishes a pull request
+// commit under ref and returns the remote path and the commit
+func newPullRequestRemote(t *testing.T, ref string) (string, string) {
+	t.Helper()
+
+	author := newTestRepo(t)
+	runGit(t, author, "commit", "-q", "--allow-empty", "-m", "pull request commit")
+	commit := runGit(t, author, "rev-parse", "HEAD")
+
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	runGit(t, author, "clone", "-q", "--bare", author, remote)
+	runGit(t, remote, "update-ref", ref, commit)
+	runGit(t, remote, "update-ref", "refs/heads/main", commit+"~1")
+
+	return remote, commit
+}
+
+func TestCheckoutPullRequest(t *testing.T) {
+	withDefaultConfig(t)
+
+	remote, commit := newPullRequestRemote(t, "refs/pull/7/head")
+	dir := newTestRepo(t)
+	runGit(t, dir, "remote", "add", "origin", remote)
+	repo := NewRepository(dir, ExecRunner{})
+
+	path, created, err := repo.CheckoutPullRequest("origin", 7, false)
+	if err != nil {
+		t.Fatalf("CheckoutPullRequest() error = %v", err)
+	}
+	if !created {
+		t.Error("CheckoutPullRequest() should report a new worktree")
+	}
+	if expected := filepath.Join(dir, ".worktrees", "pr-7"); path != expected {
+		t.Errorf("CheckoutPullRequest() path = %v, want %v", path, expected)
+	}
+	if branch := runGit(t, path, "rev-parse", "--abbrev-ref", "HEAD"); branch != "pr/7" {
+		t.Errorf("worktree is on branch %q, want pr/7", branch)
+	}
+	if head := runGit(t, path, "rev-parse", "HEAD"); head != commit {
+		t.Errorf("worktree HEAD = %v, want %v", head, commit)
+	}
+	if merge := runGit(t, dir, "config", "branch.pr/7.merge"); merge != "refs/pull/7/head" {
+		t.Errorf("branch.pr/7.merge = %v, want refs/pull/7/head", merge)
+	}
+
+	again, created, err := repo.CheckoutPullRequest("origin", 7, false)
+	if err != nil || created || again != path {
+		t.Errorf("CheckoutPullRequest() should reuse %v, got %v (created = %v, err = %v)", path, again, created, err)
+	}
+
+	if _, _, err := repo.CheckoutPullRequest("origin", 8, false); err == nil {
+		t.Error("CheckoutPullRequest() should fail for a missing pull request")
+	}
+}
+
+func TestCheckoutPullRequest_ExistingBranch(t *testing.T) {
+	withDefaultConfig(t)
+
+	remote, commit := newPullRequestRemote(t, "refs/pull/7/head")
+	dir := newTestRepo(t)
+	runGit(t, dir, "remote", "add", "origin", remote)
+	repo := NewRepository(dir, ExecRunner{})
+
+	// A branch behind the pull request is fast-forwarded
+	runGit(t, dir, "fetch", "-q", "origin", "main")
+	runGit(t, dir, "branch", "pr/7", "FETCH_HEAD")
+	path, _, err := repo.CheckoutPullRequest("origin", 7, false)
+	if err != nil {
+		t.Fatalf("CheckoutPullRequest() error = %v", err)
+	}
+	if head := runGit(t, path, "rev-parse", "HEAD"); head != commit {
+		t.Errorf("worktree HEAD = %v, want %v", head, commit)
+	}
+
+	// Local commits are never thrown away without force
+	runGit(t, path, "commit", "-q", "--allow-empty", "-m", "local commit")
+	local := runGit(t, path, "rev-parse", "HEAD")
+	runGit(t, dir, "worktree", "remove", path)
+	if _, _, err := repo.CheckoutPullRequest("origin", 7, false); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("CheckoutPullRequest() error = %v, want a hint to use --force", err)
+	}
+	if head := runGit(t, dir, "rev-parse", "pr/7"); head != local {
+		t.Errorf("pr/7 = %v, want the local commit %v kept", head, local)
+	}
+	if refs := runGit(t, dir, "for-each-ref", pullRequestFetchRef); refs != "" {
+		t.Errorf("the fetched pull request should not be left behind, got %q", refs)
+	}
+
+	path, _, err = repo.CheckoutPullRequest("origin", 7, true)
+	if err != nil {
+		t.Fatalf("CheckoutPullRequest() with force error = %v", err)
+	}
+	if head := runGit(t, path, "rev-parse", "HEAD"); head != commit {
+		t.Errorf("worktree HEAD = %v, want %v after forcing", head, commit)
+	}
+}
+
+func TestCheckoutPullRequest_GitLab(t *testing.T) {
+	withConfig(t, &Config{WorktreeDir: ".worktrees", PullRequestRefs: map[string]string{"gitlab": "gitlab"}})
+
+	remote, commit := newPullRequestRemote(t, "refs/merge-requests/3/head")
+	dir := newTestRepo(t)
+	runGit(t, dir, "remote", "add", "gitlab", remote)
+
+	path, _, err := NewRepository(dir, ExecRunner{}).CheckoutPullRequest("gitlab", 3, false)
+	if err != nil {
+		t.Fatalf("CheckoutPullRequest() error = %v", err)
+	}
+	if head := runGit(t, path, "rev-parse", "HEAD"); head != commit {
+		t.Errorf("worktree HEAD = %v, want %v", head, commit)
+	}
+}
+
+func TestPullRequestRemote(t *testing.T) {
+	withConfig(t, &Config{WorktreeDir: ".worktrees", PullRequestRefs: map[string]string{"gitlab": "gitlab"}})
+
+	tests := []struct {
+		remotes  []string
+		expected string
+	}{
+		{remotes: []string{"origin", "upstream"}, expected: "upstream"},
+		{remotes: []string{"origin"}, expected: "origin"},
+		{remotes: []string{"gitlab", "upstream"}, expected: "gitlab"},
+		{remotes: []string{"fork"}, expected: "fork"},
+		{remotes: nil, expected: "origin"},
+	}
+
+	for _, tt := range tests {
+		if result := pullRequestRemote(tt.remotes); result != tt.expected {
+			t.Errorf("pullRequestRemote(%v) = %q, want %q", tt.remotes, result, tt.expected)
+		}
+	}
+
+	appConfig.PreferredRemotes = []string{"origin"}
+	if result := pullRequestRemote([]string{"gitlab", "origin", "upstream"}); result != "origin" {
+		t.Errorf("pullRequestRemote() = %q, want the preferred remote origin", result)
+	}
+}
+
+func TestParsePullRequestNumber(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+		wantErr  bool
+	}{
+		{input: "42", expected: 42},
+		{input: "#42", expected: 42},
+		{input: "0", wantErr: true},
+		{input: "abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			number, err := parsePullRequestNumber(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePullRequestNumber() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if number != tt.expected {
+				t.Errorf("parsePullRequestNumber() = %v, want %v", number, tt.expected)
+			}
+		})
+	}
+}
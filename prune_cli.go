@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// pruneOptions holds the flags of the prune command
+type pruneOptions struct {
+	DryRun bool
+	Yes    bool
+}
+
+// HandlePruneCommand removes stale worktree entries without the TUI
+func HandlePruneCommand(args []string) {
+	fs := flag.NewFlagSet("prune", flag.ContinueOnError)
+	var opts pruneOptions
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "only show what would be pruned")
+	fs.BoolVar(&opts.Yes, "yes", false, "do not ask for confirmation")
+	fs.Usage = printPruneHelp
+
+	positional, err := parseCommandArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return
+		}
+		os.Exit(exitUsage)
+	}
+	if len(positional) != 0 {
+		printPruneHelp()
+		os.Exit(exitUsage)
+	}
+
+	if err := pruneWorktrees(currentRepository(), os.Stdout, os.Stdin, opts); err != nil {
+		fail(exitError, "%v", err)
+	}
+}
+
+func printPruneHelp() {
+	fmt.Println("Usage: worktree-util prune [--dry-run] [--yes]")
+	fmt.Println("\nRemoves worktrees whose directories no longer exist, like pressing 'p'")
+	fmt.Println("in the TUI. Locked worktrees are kept.")
+	fmt.Println("\nOptions:")
+	fmt.Println("  --dry-run    Show what would be pruned without pruning")
+	fmt.Println("  --yes        Do not ask for confirmation")
+}
+
+// pruneWorktrees lists prunable worktrees, asks for confirmation on in
+// (unless opts.Yes) and prunes them
+func pruneWorktrees(repo *Repository, w io.Writer, in io.Reader, opts pruneOptions) error {
+	prunable, err := repo.PrunableWorktrees()
+	if err != nil {
+		return err
+	}
+
+	if len(prunable) == 0 {
+		fmt.Fprintln(w, "No stale worktrees to prune")
+		return nil
+	}
+
+	for _, wt := range prunable {
+		fmt.Fprintf(w, "%s (%s)\n", wt.Path, worktreeBranchLabel(wt))
+		if wt.PrunableReason != "" {
+			fmt.Fprintf(w, "  %s\n", wt.PrunableReason)
+		}
+	}
+
+	if opts.DryRun {
+		fmt.Fprintf(w, "Dry run: %d worktree(s) would be pruned\n", len(prunable))
+		return nil
+	}
+
+	if !opts.Yes && !confirm(w, in, fmt.Sprintf("Prune %d worktree(s)?", len(prunable))) {
+		return fmt.Errorf("aborted")
+	}
+
+	if err := repo.PruneWorktrees(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "✓ Pruned %d worktree(s)\n", len(prunable))
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newPruneTestRepo creates a repo with a "stale" worktree whose directory
+// was deleted behind git's back
+func newPruneTestRepo(t *testing.T) *Repository {
+	t.Helper()
+
+	dir := newTestRepo(t)
+	if err := os.RemoveAll(addTestWorktree(t, dir, "stale")); err != nil {
+		t.Fatalf("Failed to delete worktree directory: %v", err)
+	}
+
+	return NewRepository(dir, ExecRunner{})
+}
+
+func TestPruneWorktrees(t *testing.T) {
+	repo := newPruneTestRepo(t)
+
+	var out bytes.Buffer
+	if err := pruneWorktrees(repo, &out, strings.NewReader(""), pruneOptions{DryRun: true}); err != nil {
+		t.Fatalf("pruneWorktrees() dry run error = %v", err)
+	}
+	if !strings.Contains(out.String(), "stale") || !strings.Contains(out.String(), "1 worktree(s) would be pruned") {
+		t.Errorf("dry run should list the stale worktree, got:\n%s", out.String())
+	}
+	if prunable, _ := repo.PrunableWorktrees(); len(prunable) != 1 {
+		t.Fatalf("dry run should not prune, still prunable: %v", prunable)
+	}
+
+	out.Reset()
+	if err := pruneWorktrees(repo, &out, strings.NewReader("y\n"), pruneOptions{}); err != nil {
+		t.Fatalf("pruneWorktrees() error = %v\n%s", err, out.String())
+	}
+	worktrees, err := repo.ListWorktrees()
+	if err != nil {
+		t.Fatalf("ListWorktrees() error = %v", err)
+	}
+	if len(worktrees) != 1 {
+		t.Errorf("stale worktree should be pruned, got %v", worktrees)
+	}
+}
+
+func TestPruneWorktrees_KeepsLocked(t *testing.T) {
+	repo := newPruneTestRepo(t)
+	runGit(t, repo.Dir, "worktree", "lock", filepath.Join(repo.Dir, ".worktrees", "stale"))
+
+	var out bytes.Buffer
+	if err := pruneWorktrees(repo, &out, strings.NewReader(""), pruneOptions{Yes: true}); err != nil {
+		t.Fatalf("pruneWorktrees() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "No stale worktrees") {
+		t.Errorf("locked worktree should not be offered for pruning, got:\n%s", out.String())
+	}
+}
+
+func TestPruneWorktrees_Declined(t *testing.T) {
+	repo := newPruneTestRepo(t)
+
+	var out bytes.Buffer
+	if err := pruneWorktrees(repo, &out, strings.NewReader("n\n"), pruneOptions{}); err == nil {
+		t.Error("pruneWorktrees() should abort when confirmation is declined")
+	}
+	if prunable, _ := repo.PrunableWorktrees(); len(prunable) != 1 {
+		t.Errorf("declined prune should keep the entry, prunable = %v", prunable)
+	}
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// removeOptions holds the flags of the remove command
+type removeOptions struct {
+	Force        bool
+	Trash        bool
+	DeleteBranch bool
+	DeleteRemote bool
+	DryRun       bool
+	Yes          bool
+}
+
+// HandleRemoveCommand removes one or more worktrees without the TUI
+func HandleRemoveCommand(args []string) {
+	fs := flag.NewFlagSet("remove", flag.ContinueOnError)
+	var opts removeOptions
+	fs.BoolVar(&opts.Force, "force", false, "remove worktrees with uncommitted changes and delete unmerged branches")
+	fs.BoolVar(&opts.Trash, "trash", false, "move worktrees to the trash so they can be restored")
+	fs.BoolVar(&opts.DeleteBranch, "delete-branch", appConfig != nil && appConfig.DeleteBranch, "also delete the local branch of each worktree")
+	fs.BoolVar(&opts.DeleteRemote, "delete-remote-branch", appConfig != nil && appConfig.DeleteRemoteBranch, "also delete the upstream branch of each worktree on its remote")
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "only show what would be removed")
+	fs.BoolVar(&opts.Yes, "yes", false, "do not ask for confirmation")
+	fs.Usage = printRemoveHelp
+
+	targets, err := parseCommandArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return
+		}
+		os.Exit(exitUsage)
+	}
+	if len(targets) == 0 {
+		printRemoveHelp()
+		os.Exit(exitUsage)
+	}
+
+	if err := removeWorktrees(currentRepository(), os.Stdout, os.Stdin, targets, opts); err != nil {
+		fail(exitError, "%v", err)
+	}
+}
+
+func printRemoveHelp() {
+	fmt.Println("Usage: worktree-util remove <path|branch>... [--force] [--trash] [--delete-branch] [--delete-remote-branch] [--dry-run] [--yes]")
+	fmt.Println("\nRemoves worktrees, like pressing 'd' in the TUI. Uncommitted changes and")
+	fmt.Println("commits that are on no remote and no other branch are reported before anything is deleted.")
+	fmt.Println("The main worktree is never removed.")
+	fmt.Println("\nOptions:")
+	fmt.Println("  --force            Remove locked worktrees and worktrees with uncommitted changes; with")
+	fmt.Println("                     --delete-branch also delete unmerged branches")
+	fmt.Println("  --trash            Save each worktree, uncommitted changes included, in the trash")
+	fmt.Println("                     first; see 'worktree-util trash'")
+	fmt.Println("  --delete-branch    Delete the local branch after removing its worktree")
+	fmt.Println("                     (default from delete_branch in the config)")
+	fmt.Println("  --delete-remote-branch")
+	fmt.Println("                     Delete the upstream branch on its remote as well")
+	fmt.Println("                     (default from delete_remote_branch in the config)")
+	fmt.Println("  --dry-run          Show what would be removed without removing anything")
+	fmt.Println("  --yes              Do not ask for confirmation")
+}
+
+// removalPlan is a worktree selected for removal together with its pending work
+type removalPlan struct {
+	Worktree     Worktree
+	Changes      WorktreeChanges
+	Merge        BranchMergeState
+	Remote       string // Remote of the upstream branch to delete, if any
+	RemoteBranch string
+}
+
+// removeWorktrees resolves targets, reports their state, asks for
+// confirmation on in (unless opts.Yes) and removes them
+func removeWorktrees(repo *Repository, w io.Writer, in io.Reader, targets []string, opts removeOptions) error {
+	worktrees, err := repo.ListWorktrees()
+	if err != nil {
+		return err
+	}
+
+	var plans []removalPlan
+	failed, branchFailed := 0, 0
+	for _, target := range targets {
+		wt, err := findWorktree(worktrees, target)
+		if err != nil {
+			fmt.Fprintf(w, "✗ %s: %v\n", target, err)
+			failed++
+			continue
+		}
+		if wt.IsMain {
+			fmt.Fprintf(w, "✗ %s: cannot delete main worktree\n", target)
+			failed++
+			continue
+		}
+		if wt.Locked && !opts.Force {
+			fmt.Fprintf(w, "✗ %s: %v (use --force to remove anyway)\n", target, lockedError(wt))
+			failed++
+			continue
+		}
+
+		changes, err := repo.GetWorktreeChanges(wt.Path)
+		if err != nil {
+			// A missing directory has nothing to lose; let git decide
+			changes = WorktreeChanges{}
+		}
+
+		fmt.Fprintf(w, "%s (%s)\n", wt.Path, worktreeBranchLabel(wt))
+		if n := len(changes.Uncommitted); n > 0 {
+			fmt.Fprintf(w, "  ⚠ %d uncommitted change(s)\n", n)
+		}
+		if n := len(changes.Unpushed); n > 0 {
+			fmt.Fprintf(w, "  ⚠ %d commit(s) not on any remote or other branch\n", n)
+		}
+		// The trash keeps uncommitted changes, nothing is lost
+		if len(changes.Uncommitted) > 0 && !opts.Force && !opts.Trash {
+			fmt.Fprintln(w, "  ✗ skipped: has uncommitted changes (use --force to remove anyway)")
+			failed++
+			continue
+		}
+
+		plan := removalPlan{Worktree: wt, Changes: changes}
+		if hasBranch(wt) && (opts.DeleteBranch || opts.DeleteRemote) {
+			plan.Merge, _ = repo.BranchMerged(wt.Branch)
+			if plan.Merge.Merged {
+				fmt.Fprintf(w, "  branch %s is merged into %s\n", wt.Branch, plan.Merge.Target)
+			} else {
+				fmt.Fprintf(w, "  ⚠ branch %s is not merged into %s\n", wt.Branch, plan.Merge.Target)
+				if opts.DeleteBranch && !opts.Force {
+					fmt.Fprintln(w, "    it will be kept (use --force to delete it anyway)")
+				}
+			}
+		}
+		if hasBranch(wt) && opts.DeleteRemote {
+			plan.Remote, plan.RemoteBranch, _ = repo.BranchUpstream(wt.Branch)
+			switch {
+			case plan.Remote == "":
+				fmt.Fprintf(w, "  branch %s has no remote branch to delete\n", wt.Branch)
+			case !plan.Merge.MergedIntoDefault && !opts.Force:
+				fmt.Fprintf(w, "  ⚠ remote branch %s/%s is not merged into %s, it will be kept (use --force to delete it anyway)\n",
+					plan.Remote, plan.RemoteBranch, plan.Merge.Default)
+				plan.Remote, plan.RemoteBranch = "", ""
+			default:
+				fmt.Fprintf(w, "  remote branch %s/%s will be deleted\n", plan.Remote, plan.RemoteBranch)
+			}
+		}
+
+		plans = append(plans, plan)
+	}
+
+	if opts.DryRun {
+		fmt.Fprintf(w, "Dry run: %d worktree(s) would be removed\n", len(plans))
+		return removalResult(failed, 0)
+	}
+
+	if len(plans) == 0 {
+		return removalResult(failed, 0)
+	}
+
+	if !opts.Yes && !confirm(w, in, fmt.Sprintf("Remove %d worktree(s)?", len(plans))) {
+		return fmt.Errorf("aborted")
+	}
+
+	for _, plan := range plans {
+		wt := plan.Worktree
+		id, err := repo.RemoveWorktreeWithOptions(wt.Path, RemoveOptions{Force: opts.Force, Trash: opts.Trash})
+		if err != nil {
+			fmt.Fprintf(w, "✗ %s: %v\n", wt.Path, strings.TrimSpace(err.Error()))
+			failed++
+			continue
+		}
+		if id != "" {
+			fmt.Fprintf(w, "✓ Worktree moved to trash: %s (restore with 'worktree-util trash restore %s')\n", wt.Path, id)
+		} else {
+			fmt.Fprintf(w, "✓ Worktree removed: %s\n", wt.Path)
+		}
+
+		if opts.DeleteBranch && hasBranch(wt) && (plan.Merge.Merged || opts.Force) {
+			if err := repo.DeleteBranch(wt.Branch, opts.Force); err != nil {
+				fmt.Fprintf(w, "✗ %s: %v\n", wt.Branch, err)
+				branchFailed++
+				// Keep the remote branch, it may be the only other copy
+				continue
+			}
+			fmt.Fprintf(w, "✓ Branch deleted: %s\n", wt.Branch)
+		}
+
+		// The upstream was recorded in the plan, deleting the local branch
+		// does not lose it
+		if plan.Remote != "" {
+			if err := repo.DeleteRemoteBranch(plan.Remote, plan.RemoteBranch); err != nil {
+				fmt.Fprintf(w, "✗ %s/%s: %v\n", plan.Remote, plan.RemoteBranch, err)
+				branchFailed++
+				continue
+			}
+			fmt.Fprintf(w, "✓ Remote branch deleted: %s/%s\n", plan.Remote, plan.RemoteBranch)
+		}
+	}
+
+	return removalResult(failed, branchFailed)
+}
+
+// removalResult turns the failure counts into the command's error
+func removalResult(failed, branchFailed int) error {
+	var problems []string
+	if failed > 0 {
+		problems = append(problems, fmt.Sprintf("%d worktree(s) could not be removed", failed))
+	}
+	if branchFailed > 0 {
+		problems = append(problems, fmt.Sprintf("%d branch(es) could not be deleted", branchFailed))
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// findWorktree looks a worktree up by path or branch name
+func findWorktree(worktrees []Worktree, target string) (Worktree, error) {
+	abs, err := filepath.Abs(target)
+	if err == nil {
+		if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+			abs = resolved
+		}
+		for _, wt := range worktrees {
+			if filepath.Clean(wt.Path) == abs {
+				return wt, nil
+			}
+		}
+	}
+
+	for _, wt := range worktrees {
+		if wt.Branch == target {
+			return wt, nil
+		}
+	}
+
+	return Worktree{}, fmt.Errorf("no worktree found for path or branch")
+}
+
+// hasBranch reports whether a worktree has a branch checked out
+func hasBranch(wt Worktree) bool {
+	return wt.Branch != "" && wt.Branch != "detached"
+}
+
+// worktreeBranchLabel returns a short description of the worktree's branch
+func worktreeBranchLabel(wt Worktree) string {
+	if !hasBranch(wt) {
+		return fmt.Sprintf("commit %.7s", wt.Commit)
+	}
+	return "branch " + wt.Branch
+}
+
+// confirm asks a yes/no question and returns true only for an explicit yes
+func confirm(w io.Writer, in io.Reader, question string) bool {
+	fmt.Fprintf(w, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newRemoveTestRepo creates a repo with a clean "feature" worktree and
+// returns the repository and the worktree path
+func newRemoveTestRepo(t *testing.T) (*Repository, string) {
+	t.Helper()
+
+	dir := newTestRepo(t)
+	path := addTestWorktree(t, dir, "feature")
+
+	return NewRepository(dir, ExecRunner{}), path
+}
+
+func TestRemoveWorktrees_RefusesMain(t *testing.T) {
+	repo, _ := newRemoveTestRepo(t)
+
+	var out bytes.Buffer
+	err := removeWorktrees(repo, &out, strings.NewReader(""), []string{repo.Dir}, removeOptions{Yes: true})
+	if err == nil {
+		t.Fatal("removeWorktrees() should fail for the main worktree")
+	}
+	if !strings.Contains(out.String(), "cannot delete main worktree") {
+		t.Errorf("output should explain main worktree refusal, got:\n%s", out.String())
+	}
+}
+
+func TestRemoveWorktrees_DryRun(t *testing.T) {
+	repo, path := newRemoveTestRepo(t)
+
+	var out bytes.Buffer
+	if err := removeWorktrees(repo, &out, strings.NewReader(""), []string{"feature"}, removeOptions{DryRun: true}); err != nil {
+		t.Fatalf("removeWorktrees() error = %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("dry run should keep the worktree: %v", err)
+	}
+	if !strings.Contains(out.String(), "would be removed") {
+		t.Errorf("output should describe dry run, got:\n%s", out.String())
+	}
+}
+
+func TestRemoveWorktrees_DirtyNeedsForce(t *testing.T) {
+	repo, path := newRemoveTestRepo(t)
+	if err := os.WriteFile(filepath.Join(path, "new.txt"), []byte("wip"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := removeWorktrees(repo, &out, strings.NewReader(""), []string{path}, removeOptions{Yes: true}); err == nil {
+		t.Error("removeWorktrees() should fail for dirty worktree without --force")
+	}
+	if !strings.Contains(out.String(), "1 uncommitted change(s)") {
+		t.Errorf("output should report uncommitted changes, got:\n%s", out.String())
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("dirty worktree should be kept: %v", err)
+	}
+
+	out.Reset()
+	if err := removeWorktrees(repo, &out, strings.NewReader(""), []string{path}, removeOptions{Yes: true, Force: true}); err != nil {
+		t.Fatalf("removeWorktrees() with --force error = %v\n%s", err, out.String())
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("worktree should be removed with --force")
+	}
+}
+
+func TestRemoveWorktrees_ConfirmAndDeleteBranch(t *testing.T) {
+	repo, path := newRemoveTestRepo(t)
+
+	var out bytes.Buffer
+	if err := removeWorktrees(repo, &out, strings.NewReader("n\n"), []string{"feature"}, removeOptions{DeleteBranch: true}); err == nil {
+		t.Error("removeWorktrees() should abort when confirmation is declined")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("declined removal should keep the worktree: %v", err)
+	}
+
+	out.Reset()
+	if err := removeWorktrees(repo, &out, strings.NewReader("y\n"), []string{"feature"}, removeOptions{DeleteBranch: true}); err != nil {
+		t.Fatalf("removeWorktrees() error = %v\n%s", err, out.String())
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("worktree should be removed after confirmation")
+	}
+	if branches := runGit(t, repo.Dir, "branch", "--list", "feature"); branches != "" {
+		t.Errorf("branch should be deleted, got %q", branches)
+	}
+}
+
+func TestGetWorktreeChanges_Unpushed(t *testing.T) {
+	repo, path := newRemoveTestRepo(t)
+	runGit(t, path, "commit", "-q", "--allow-empty", "-m", "local work")
+
+	changes, err := repo.GetWorktreeChanges(path)
+	if err != nil {
+		t.Fatalf("GetWorktreeChanges() error = %v", err)
+	}
+	if len(changes.Uncommitted) != 0 {
+		t.Errorf("Uncommitted = %v, want none", changes.Uncommitted)
+	}
+	// The initial commit is on main, only the new one would be lost
+	if len(changes.Unpushed) != 1 || !strings.Contains(changes.Unpushed[0], "local work") {
+		t.Errorf("Unpushed = %v, want the local work commit", changes.Unpushed)
+	}
+	if !changes.HasChanges() {
+		t.Error("HasChanges() should be true with unpushed commits")
+	}
+}
+
+func TestGetWorktreeChanges_NoRemotes(t *testing.T) {
+	repo, path := newRemoveTestRepo(t)
+	runGit(t, repo.Dir, "commit", "-q", "--allow-empty", "-m", "second")
+	runGit(t, path, "merge", "-q", "--ff-only", "main")
+
+	changes, err := repo.GetWorktreeChanges(path)
+	if err != nil {
+		t.Fatalf("GetWorktreeChanges() error = %v", err)
+	}
+	if changes.HasChanges() {
+		t.Errorf("a worktree identical to main in a repo without remotes has nothing to lose, got %+v", changes)
+	}
+
+	// A detached worktree on a branch's commit has nothing to lose either
+	detached := filepath.Join(repo.Dir, ".worktrees", "detached")
+	runGit(t, repo.Dir, "worktree", "add", "-q", "--detach", detached, "main")
+	if changes, _ := repo.GetWorktreeChanges(detached); changes.HasChanges() {
+		t.Errorf("detached worktree at main got %+v", changes)
+	}
+}
+
+func TestRemoveWorktrees_LockedNeedsForce(t *testing.T) {
+	repo, path := newRemoveTestRepo(t)
+	runGit(t, repo.Dir, "worktree", "lock", "--reason", "usb drive", path)
+
+	var out bytes.Buffer
+	if err := removeWorktrees(repo, &out, strings.NewReader(""), []string{"feature"}, removeOptions{Yes: true}); err == nil {
+		t.Error("removeWorktrees() should fail for locked worktree without --force")
+	}
+	if !strings.Contains(out.String(), "worktree is locked: usb drive") {
+		t.Errorf("output should report the lock reason, got:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := removeWorktrees(repo, &out, strings.NewReader(""), []string{"feature"}, removeOptions{Yes: true, Force: true}); err != nil {
+		t.Fatalf("removeWorktrees() with --force error = %v\n%s", err, out.String())
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("locked worktree should be removed with --force")
+	}
+}
+
+// newRemoteTestRepo clones a repository with a feature branch that is
+// pushed to origin, checks feature out in a worktree and returns the
+// repository, the worktree path and the remote
+func newRemoteTestRepo(t *testing.T) (*Repository, string, string) {
+	t.Helper()
+
+	origin := newTestRepo(t)
+	runGit(t, origin, "branch", "feature")
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	runGit(t, origin, "clone", "-q", "--bare", origin, remote)
+
+	dir := filepath.Join(t.TempDir(), "clone")
+	runGit(t, origin, "clone", "-q", remote, dir)
+	path := filepath.Join(dir, ".worktrees", "feature")
+	runGit(t, dir, "worktree", "add", "-q", path, "feature")
+
+	return NewRepository(dir, ExecRunner{}), path, remote
+}
+
+func TestBranchUpstream(t *testing.T) {
+	repo, _, _ := newRemoteTestRepo(t)
+
+	remote, branch, err := repo.BranchUpstream("feature")
+	if err != nil {
+		t.Fatalf("BranchUpstream() error = %v", err)
+	}
+	if remote != "origin" || branch != "feature" {
+		t.Errorf("BranchUpstream() = %q, %q, want origin, feature", remote, branch)
+	}
+
+	runGit(t, repo.Dir, "branch", "local-only")
+	if remote, branch, err := repo.BranchUpstream("local-only"); err != nil || remote != "" || branch != "" {
+		t.Errorf("BranchUpstream() = %q, %q, %v, want no upstream", remote, branch, err)
+	}
+}
+
+func TestBranchMerged(t *testing.T) {
+	repo, path := newRemoveTestRepo(t)
+
+	state, err := repo.BranchMerged("feature")
+	if err != nil {
+		t.Fatalf("BranchMerged() error = %v", err)
+	}
+	if !state.Merged || state.Target != "main" {
+		t.Errorf("BranchMerged() = %+v, want merged into main", state)
+	}
+
+	runGit(t, path, "commit", "-q", "--allow-empty", "-m", "local work")
+	if state, _ := repo.BranchMerged("feature"); state.Merged {
+		t.Error("BranchMerged() should be false after a new commit")
+	}
+}
+
+func TestRemoveWorktrees_DeleteRemoteBranch(t *testing.T) {
+	repo, path, remote := newRemoteTestRepo(t)
+
+	var out bytes.Buffer
+	opts := removeOptions{Yes: true, DeleteBranch: true, DeleteRemote: true}
+	if err := removeWorktrees(repo, &out, strings.NewReader(""), []string{path}, opts); err != nil {
+		t.Fatalf("removeWorktrees() error = %v\n%s", err, out.String())
+	}
+	for _, want := range []string{"merged into origin/feature", "✓ Remote branch deleted: origin/feature", "✓ Branch deleted: feature"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output should contain %q, got:\n%s", want, out.String())
+		}
+	}
+	if branches := runGit(t, remote, "branch", "--list", "feature"); branches != "" {
+		t.Errorf("remote branch should be deleted, got %q", branches)
+	}
+}
+
+func TestRemoveWorktrees_KeepsUnmergedRemoteBranch(t *testing.T) {
+	repo, path, remote := newRemoteTestRepo(t)
+	runGit(t, path, "commit", "-q", "--allow-empty", "-m", "pushed but not merged")
+	runGit(t, path, "push", "-q", "origin", "feature")
+
+	var out bytes.Buffer
+	opts := removeOptions{Yes: true, DeleteBranch: true, DeleteRemote: true}
+	if err := removeWorktrees(repo, &out, strings.NewReader(""), []string{path}, opts); err != nil {
+		t.Fatalf("removeWorktrees() error = %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "remote branch origin/feature is not merged into origin/main") {
+		t.Errorf("output should explain why the remote branch is kept, got:\n%s", out.String())
+	}
+	// The local branch is merged into its upstream, which still has the commit
+	if branches := runGit(t, repo.Dir, "branch", "--list", "feature"); branches != "" {
+		t.Errorf("local branch should be deleted, got %q", branches)
+	}
+	if branches := runGit(t, remote, "branch", "--list", "feature"); branches == "" {
+		t.Error("unmerged remote branch should be kept without --force")
+	}
+}
+
+func TestRemovalResult(t *testing.T) {
+	if err := removalResult(0, 0); err != nil {
+		t.Errorf("removalResult(0, 0) = %v, want nil", err)
+	}
+	err := removalResult(0, 2)
+	if err == nil || strings.Contains(err.Error(), "worktree") || !strings.Contains(err.Error(), "2 branch(es) could not be deleted") {
+		t.Errorf("removalResult(0, 2) = %v, want only the branch failures", err)
+	}
+}
+
+func TestRemoveWorktrees_KeepsUnmergedBranch(t *testing.T) {
+	repo, path := newRemoveTestRepo(t)
+	runGit(t, path, "commit", "-q", "--allow-empty", "-m", "local work")
+
+	var out bytes.Buffer
+	if err := removeWorktrees(repo, &out, strings.NewReader(""), []string{path}, removeOptions{Yes: true, DeleteBranch: true}); err != nil {
+		t.Fatalf("removeWorktrees() error = %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "is not merged into main") {
+		t.Errorf("output should report the unmerged branch, got:\n%s", out.String())
+	}
+	if branches := runGit(t, repo.Dir, "branch", "--list", "feature"); branches == "" {
+		t.Error("unmerged branch should be kept without --force")
+	}
+}
+
+func TestWorktreeBranchLabel(t *testing.T) {
+	tests := []struct {
+		wt       Worktree
+		expected string
+	}{
+		{wt: Worktree{Branch: "feature", Commit: "abcdef1234"}, expected: "branch feature"},
+		{wt: Worktree{Branch: "detached", Commit: "abcdef1234"}, expected: "commit abcdef1"},
+		{wt: Worktree{Commit: "abcdef1234"}, expected: "commit abcdef1"},
+	}
+
+	for _, tt := range tests {
+		if result := worktreeBranchLabel(tt.wt); result != tt.expected {
+			t.Errorf("worktreeBranchLabel(%+v) = %q, want %q", tt.wt, result, tt.expected)
+		}
+	}
+}
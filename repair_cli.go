@@ -0,0 +1,125 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// repairOptions holds the flags of the repair command
+type repairOptions struct {
+	DryRun bool
+	Yes    bool
+}
+
+// HandleRepairCommand fixes worktree links after the repository was moved
+func HandleRepairCommand(args []string) {
+	fs := flag.NewFlagSet("repair", flag.ContinueOnError)
+	var opts repairOptions
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "only show what would be repaired")
+	fs.BoolVar(&opts.Yes, "yes", false, "do not ask for confirmation")
+	fs.Usage = printRepairHelp
+
+	paths, err := parseCommandArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return
+		}
+		os.Exit(exitUsage)
+	}
+
+	if err := repairWorktrees(currentRepository(), os.Stdout, os.Stdin, paths, opts); err != nil {
+		fail(exitError, "%v", err)
+	}
+}
+
+func printRepairHelp() {
+	fmt.Println("Usage: worktree-util repair [<path>...] [--dry-run] [--yes]")
+	fmt.Println("\nRepairs worktree links after the main repository was moved, like pressing")
+	fmt.Println("'R' in the TUI. Without paths, linked worktrees and the directories in")
+	fmt.Println("worktree_dir are checked for .git files pointing nowhere.")
+	fmt.Println("\nOptions:")
+	fmt.Println("  --dry-run    Show what would be repaired without changing anything")
+	fmt.Println("  --yes        Do not ask for confirmation")
+}
+
+// repairWorktrees detects (or takes) broken worktrees, asks for confirmation
+// on in (unless opts.Yes), repairs them and verifies the result
+func repairWorktrees(repo *Repository, w io.Writer, in io.Reader, paths []string, opts repairOptions) error {
+	var broken []BrokenWorktree
+	if len(paths) > 0 {
+		for _, path := range paths {
+			abs, err := filepath.Abs(path)
+			if err != nil {
+				return err
+			}
+			broken = append(broken, BrokenWorktree{Path: abs, Reason: "requested"})
+		}
+	} else {
+		detected, err := repo.FindBrokenWorktrees()
+		if err != nil {
+			return err
+		}
+		broken = detected
+	}
+
+	if len(broken) == 0 {
+		fmt.Fprintln(w, "No broken worktrees found")
+		return nil
+	}
+
+	for _, b := range broken {
+		fmt.Fprintf(w, "%s\n  %s", b.Path, b.Reason)
+		if b.GitDir != "" {
+			fmt.Fprintf(w, ": %s", b.GitDir)
+		}
+		fmt.Fprintln(w)
+	}
+
+	if opts.DryRun {
+		fmt.Fprintf(w, "Dry run: %d worktree(s) would be repaired\n", len(broken))
+		return nil
+	}
+
+	if !opts.Yes && !confirm(w, in, fmt.Sprintf("Repair %d worktree(s)?", len(broken))) {
+		return fmt.Errorf("aborted")
+	}
+
+	if err := repo.RepairWorktrees(brokenPaths(broken)); err != nil {
+		return err
+	}
+
+	// Verify that git now sees healthy worktrees
+	remaining, err := repo.FindBrokenWorktrees()
+	if err != nil {
+		return err
+	}
+	worktrees, err := repo.ListWorktrees()
+	if err != nil {
+		return err
+	}
+	stale := prunableWorktrees(worktrees)
+
+	fmt.Fprintf(w, "✓ Repaired %d worktree(s)\n", len(broken)-len(remaining))
+	for _, b := range remaining {
+		fmt.Fprintf(w, "✗ still broken: %s (%s)\n", b.Path, b.Reason)
+	}
+	for _, wt := range stale {
+		fmt.Fprintf(w, "⚠ still prunable: %s (run 'worktree-util prune' if it was deleted)\n", wt.Path)
+	}
+	if len(remaining) > 0 {
+		return fmt.Errorf("%d worktree(s) could not be repaired", len(remaining))
+	}
+	return nil
+}
+
+// brokenPaths returns the paths of broken worktrees
+func brokenPaths(broken []BrokenWorktree) []string {
+	paths := make([]string, len(broken))
+	for i, b := range broken {
+		paths[i] = b.Path
+	}
+	return paths
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newMovedTestRepo creates a repo with a worktree in .worktrees, moves the
+// whole repository to a new directory and returns it
+func newMovedTestRepo(t *testing.T) *Repository {
+	t.Helper()
+
+	withDefaultConfig(t)
+
+	dir := newTestRepo(t)
+	addTestWorktree(t, dir, "feature")
+
+	moved := dir + "-moved"
+	if err := os.Rename(dir, moved); err != nil {
+		t.Fatalf("Failed to move repository: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(moved) })
+
+	return NewRepository(moved, ExecRunner{})
+}
+
+func TestFindBrokenWorktrees_MovedRepository(t *testing.T) {
+	repo := newMovedTestRepo(t)
+
+	broken, err := repo.FindBrokenWorktrees()
+	if err != nil {
+		t.Fatalf("FindBrokenWorktrees() error = %v", err)
+	}
+	if len(broken) != 1 {
+		t.Fatalf("FindBrokenWorktrees() = %v, want 1 broken worktree", broken)
+	}
+	if expected := filepath.Join(repo.Dir, ".worktrees", "feature"); broken[0].Path != expected {
+		t.Errorf("broken worktree path = %v, want %v", broken[0].Path, expected)
+	}
+	if !strings.Contains(broken[0].Reason, "non-existent") {
+		t.Errorf("broken worktree reason = %q", broken[0].Reason)
+	}
+}
+
+func TestRepairWorktrees_MovedRepository(t *testing.T) {
+	repo := newMovedTestRepo(t)
+
+	var out bytes.Buffer
+	if err := repairWorktrees(repo, &out, strings.NewReader(""), nil, repairOptions{DryRun: true}); err != nil {
+		t.Fatalf("repairWorktrees() dry run error = %v", err)
+	}
+	if !strings.Contains(out.String(), "1 worktree(s) would be repaired") {
+		t.Errorf("dry run output:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := repairWorktrees(repo, &out, strings.NewReader("y\n"), nil, repairOptions{}); err != nil {
+		t.Fatalf("repairWorktrees() error = %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "✓ Repaired 1 worktree(s)") || strings.Contains(out.String(), "still") {
+		t.Errorf("repair output:\n%s", out.String())
+	}
+
+	worktrees, err := repo.ListWorktrees()
+	if err != nil {
+		t.Fatalf("ListWorktrees() error = %v", err)
+	}
+	if len(worktrees) != 2 || worktrees[1].Prunable || worktrees[1].Path != filepath.Join(repo.Dir, ".worktrees", "feature") {
+		t.Errorf("worktrees after repair = %+v", worktrees)
+	}
+}
+
+func TestRepairWorktrees_Healthy(t *testing.T) {
+	dir := newTestRepo(t)
+	addTestWorktree(t, dir, "feature")
+
+	var out bytes.Buffer
+	if err := repairWorktrees(NewRepository(dir, ExecRunner{}), &out, strings.NewReader(""), nil, repairOptions{}); err != nil {
+		t.Fatalf("repairWorktrees() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "No broken worktrees") {
+		t.Errorf("healthy repository output:\n%s", out.String())
+	}
+}
+
+func TestReadGitFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".git"), []byte("gitdir: ../repo/.git/worktrees/x\n"), 0644); err != nil {
+		t.Fatalf("Failed to write .git file: %v", err)
+	}
+
+	gitDir, err := readGitFile(dir)
+	if err != nil {
+		t.Fatalf("readGitFile() error = %v", err)
+	}
+	if expected := filepath.Join(filepath.Dir(dir), "repo", ".git", "worktrees", "x"); gitDir != expected {
+		t.Errorf("readGitFile() = %v, want %v", gitDir, expected)
+	}
+
+	if _, err := readGitFile(t.TempDir()); err == nil {
+		t.Error("readGitFile() should fail without a .git file")
+	}
+}
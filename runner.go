@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// GitRunner runs git commands. ExecRunner shells out to the git binary;
+// tests use a scripted fake so the tool can be driven without a real repo.
+type GitRunner interface {
+	// Run executes git with args in dir and returns its stdout.
+	// When git fails the error is a *GitError carrying its stderr.
+	Run(dir string, args ...string) (string, error)
+}
+
+// GitError is returned by a GitRunner when a git command fails
+type GitError struct {
+	Args   []string
+	Stderr string
+	Err    error
+}
+
+// Error returns git's error output, or the underlying error if there is none
+func (e *GitError) Error() string {
+	if msg := strings.TrimSpace(e.Stderr); msg != "" {
+		return msg
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *GitError) Unwrap() error {
+	return e.Err
+}
+
+// ExecRunner runs the git binary found in PATH
+type ExecRunner struct{}
+
+// Run executes git with args in dir
+func (ExecRunner) Run(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var out bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return out.String(), &GitError{Args: args, Stderr: stderr.String(), Err: err}
+	}
+
+	return out.String(), nil
+}
+
+// Repository is a git repository accessed through a GitRunner.
+// All commands run in Dir, which may be the repository root or any
+// directory inside it.
+type Repository struct {
+	Dir    string
+	runner GitRunner
+}
+
+// NewRepository returns a repository rooted at dir that runs git through runner
+func NewRepository(dir string, runner GitRunner) *Repository {
+	return &Repository{Dir: dir, runner: runner}
+}
+
+// currentRepository returns the repository containing the working directory
+func currentRepository() *Repository {
+	dir, err := os.Getwd()
+	if err != nil {
+		dir = "."
+	}
+	return NewRepository(dir, ExecRunner{})
+}
+
+// git runs a git command in the repository directory
+func (r *Repository) git(args ...string) (string, error) {
+	return r.runner.Run(r.Dir, args...)
+}
+
+// gitIn runs a git command in another directory, such as a linked worktree
+func (r *Repository) gitIn(dir string, args ...string) (string, error) {
+	return r.runner.Run(dir, args...)
+}
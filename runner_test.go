@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeCall records a git invocation made through fakeRunner
+type fakeCall struct {
+	Dir  string
+	Args []string
+}
+
+// fakeResponse is the scripted result of a git invocation
+type fakeResponse struct {
+	Stdout string
+	Stderr string
+	Fail   bool
+}
+
+// fakeRunner is a scriptable GitRunner. Responses are keyed by the git
+// arguments joined with spaces; unscripted commands fail.
+type fakeRunner struct {
+	mu        sync.Mutex
+	responses map[string]fakeResponse
+	calls     []fakeCall
+}
+
+func newFakeRunner() *fakeRunner {
+	return &fakeRunner{responses: map[string]fakeResponse{}}
+}
+
+// on scripts a successful command
+func (f *fakeRunner) on(args string, stdout string) *fakeRunner {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses[args] = fakeResponse{Stdout: stdout}
+	return f
+}
+
+// onError scripts a failing command
+func (f *fakeRunner) onError(args string, stderr string) *fakeRunner {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses[args] = fakeResponse{Stderr: stderr, Fail: true}
+	return f
+}
+
+// Run returns the scripted response for args
+func (f *fakeRunner) Run(dir string, args ...string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, fakeCall{Dir: dir, Args: args})
+
+	key := strings.Join(args, " ")
+	resp, ok := f.responses[key]
+	if !ok {
+		return "", &GitError{Args: args, Stderr: "fake: unexpected git " + key, Err: errors.New("exit status 1")}
+	}
+	if resp.Fail {
+		return resp.Stdout, &GitError{Args: args, Stderr: resp.Stderr, Err: errors.New("exit status 1")}
+	}
+	return resp.Stdout, nil
+}
+
+// called reports whether git was invoked with exactly args
+func (f *fakeRunner) called(args string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, call := range f.calls {
+		if strings.Join(call.Args, " ") == args {
+			return true
+		}
+	}
+	return false
+}
+
+func TestExecRunner_Run(t *testing.T) {
+	repo := newTestRepo(t)
+
+	out, err := ExecRunner{}.Run(repo, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if strings.TrimSpace(out) != "main" {
+		t.Errorf("Run() = %q, want main", out)
+	}
+
+	_, err = ExecRunner{}.Run(repo, "rev-parse", "--verify", "does-not-exist")
+	var gitErr *GitError
+	if !errors.As(err, &gitErr) {
+		t.Fatalf("Run() error = %v, want *GitError", err)
+	}
+	if gitErr.Stderr == "" {
+		t.Error("GitError should carry git's stderr")
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Error("GitError should unwrap to *exec.ExitError")
+	}
+}
+
+func TestGitError_Error(t *testing.T) {
+	err := &GitError{Stderr: "fatal: bad thing\n", Err: errors.New("exit status 128")}
+	if err.Error() != "fatal: bad thing" {
+		t.Errorf("Error() = %q, want trimmed stderr", err.Error())
+	}
+
+	err = &GitError{Err: errors.New("exit status 128")}
+	if err.Error() != "exit status 128" {
+		t.Errorf("Error() = %q, want underlying error", err.Error())
+	}
+}
+
+func TestRepository_UsesDir(t *testing.T) {
+	fake := newFakeRunner().on("rev-parse --show-toplevel", "/repo\n")
+	repo := NewRepository("/repo/sub", fake)
+
+	if _, err := repo.Root(); err != nil {
+		t.Fatalf("Root() error = %v", err)
+	}
+	if len(fake.calls) != 1 || fake.calls[0].Dir != "/repo/sub" {
+		t.Errorf("git should run in the repository directory, calls = %v", fake.calls)
+	}
+}
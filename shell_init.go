@@ -0,0 +1,274 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// commandNames are the subcommands offered by shell completion
+var commandNames = []string{"list", "add", "checkout", "pr", "remove", "prune", "lock", "unlock", "move", "repair", "trash", "config", "shell-init", "help"}
+
+// commandFlags are the flags offered by shell completion for each subcommand
+var commandFlags = map[string][]string{
+	"list":     {"--format", "--template"},
+	"add":      {"--base", "--path", "--no-copy", "--cd"},
+	"checkout": {"--detach", "--cd"},
+	"pr":       {"--remote", "--force", "--cd"},
+	"remove":   {"--force", "--trash", "--delete-branch", "--delete-remote-branch", "--dry-run", "--yes"},
+	"prune":    {"--dry-run", "--yes"},
+	"lock":     {"--reason"},
+	"move":     {"--to-branch-name", "--rename-branch", "--force"},
+	"repair":   {"--dry-run", "--yes"},
+	"trash":    {"--cd", "--all", "--yes"},
+}
+
+// commandAliases maps short subcommand names to their full names
+var commandAliases = map[string]string{
+	"ls": "list",
+	"co": "checkout",
+	"rm": "remove",
+	"mv": "move",
+}
+
+// configCommands are the subcommands of the config command
+var configCommands = []string{"init", "set", "get", "add-copy-file", "remove-copy-file"}
+
+// supportedShells are the shells shell-init can generate code for
+var supportedShells = []string{"bash", "zsh", "fish"}
+
+// HandleShellInitCommand prints the wrapper function and completions for a shell
+func HandleShellInitCommand(args []string) {
+	if len(args) != 1 {
+		fmt.Println("Usage: worktree-util shell-init bash|zsh|fish")
+		fmt.Println("\nAdd one of these to your shell configuration:")
+		fmt.Println(`  eval "$(worktree-util shell-init bash)"    # ~/.bashrc`)
+		fmt.Println(`  eval "$(worktree-util shell-init zsh)"     # ~/.zshrc`)
+		fmt.Println("  worktree-util shell-init fish | source     # ~/.config/fish/config.fish")
+		os.Exit(exitUsage)
+	}
+
+	if err := writeShellInit(os.Stdout, args[0]); err != nil {
+		fail(exitUsage, "%v", err)
+	}
+}
+
+// writeShellInit writes the shell integration script for shell to w
+func writeShellInit(w io.Writer, shell string) error {
+	var script string
+	switch shell {
+	case "bash":
+		script = posixWrapper + bashCompletion
+	case "zsh":
+		script = posixWrapper + zshCompletion
+	case "fish":
+		script = fishInit
+	default:
+		return fmt.Errorf("unsupported shell '%s' (available: %s)", shell, strings.Join(supportedShells, ", "))
+	}
+	_, err := io.WriteString(w, script)
+	return err
+}
+
+// HandleCompleteCommand prints completion candidates for the given words.
+// The last word is the one being completed (possibly empty). Errors, such as
+// running outside a git repository, simply produce no candidates.
+func HandleCompleteCommand(words []string) {
+	for _, candidate := range completionCandidates(currentRepository(), words) {
+		fmt.Println(candidate)
+	}
+}
+
+// completionCandidates returns the candidates matching the last word,
+// given the words typed before it
+func completionCandidates(repo *Repository, words []string) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	current := words[len(words)-1]
+	prev := words[:len(words)-1]
+
+	var candidates []string
+	if len(prev) == 0 {
+		candidates = commandNames
+	} else {
+		candidates = argumentCandidates(repo, prev, current)
+	}
+
+	var matches []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, current) {
+			matches = append(matches, candidate)
+		}
+	}
+	return matches
+}
+
+// argumentCandidates returns the candidates for an argument of a subcommand
+func argumentCandidates(repo *Repository, prev []string, current string) []string {
+	cmd := prev[0]
+	if alias, ok := commandAliases[cmd]; ok {
+		cmd = alias
+	}
+	last := prev[len(prev)-1]
+
+	if strings.HasPrefix(current, "-") {
+		return commandFlags[cmd]
+	}
+
+	switch cmd {
+	case "list":
+		if last == "--format" {
+			return listFormats
+		}
+	case "add":
+		if last == "--base" {
+			return refNames(repo)
+		}
+	case "checkout":
+		if len(prev) == 1 {
+			return refNames(repo)
+		}
+	case "pr":
+		if last == "--remote" {
+			remotes, _ := repo.GetRemotes()
+			return remotes
+		}
+	case "remove":
+		return worktreeNames(repo)
+	case "lock", "unlock", "move":
+		if len(prev) == 1 {
+			return worktreeNames(repo)
+		}
+	case "config":
+		if len(prev) == 1 {
+			return configCommands
+		}
+		if len(prev) == 2 && prev[1] == "get" {
+			return configKeys
+		}
+		if len(prev) == 2 && prev[1] == "set" {
+			return configSetKeys
+		}
+	case "trash":
+		if len(prev) == 1 {
+			return trashCommands
+		}
+		if prev[1] == "restore" || prev[1] == "purge" {
+			return trashIDs(repo)
+		}
+	case "shell-init":
+		if len(prev) == 1 {
+			return supportedShells
+		}
+	}
+	return nil
+}
+
+// refNames returns local and remote branch names followed by tags for completion
+func refNames(repo *Repository) []string {
+	refs, err := repo.BaseRefs()
+	if err != nil {
+		return nil
+	}
+	return refs
+}
+
+// worktreeNames returns the branches (or paths, when detached) of all
+// removable worktrees for completion
+func worktreeNames(repo *Repository) []string {
+	worktrees, err := repo.ListWorktrees()
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, wt := range worktrees {
+		if wt.IsMain {
+			continue
+		}
+		if wt.Branch != "" && wt.Branch != "detached" {
+			names = append(names, wt.Branch)
+		} else {
+			names = append(names, wt.Path)
+		}
+	}
+	return names
+}
+
+// trashIDs returns the IDs of trashed worktrees for completion
+func trashIDs(repo *Repository) []string {
+	entries, err := repo.ListTrash()
+	if err != nil {
+		return nil
+	}
+	var ids []string
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+	return ids
+}
+
+// posixWrapper is the wt function shared by bash and zsh
+const posixWrapper = `# worktree-util shell integration
+wt() {
+    local cdfile
+    cdfile=$(mktemp "${TMPDIR:-/tmp}/worktree-util-cd.XXXXXX") || return 1
+    command worktree-util --cd-file "$cdfile" "$@"
+    local exit_status=$?
+    if [ -s "$cdfile" ]; then
+        local target
+        target=$(cat "$cdfile")
+        if [ -d "$target" ]; then
+            cd "$target"
+        fi
+    fi
+    rm -f "$cdfile"
+    return $exit_status
+}
+`
+
+const bashCompletion = `
+_worktree_util_complete() {
+    local IFS=$'\n'
+    COMPREPLY=($(command worktree-util __complete "${COMP_WORDS[@]:1:COMP_CWORD}"))
+}
+complete -o default -F _worktree_util_complete wt worktree-util
+`
+
+const zshCompletion = `
+_worktree_util_complete() {
+    local -a candidates
+    candidates=(${(f)"$(command worktree-util __complete "${(@)words[2,CURRENT]}")"})
+    compadd -a candidates
+}
+if (( $+functions[compdef] )); then
+    compdef _worktree_util_complete wt worktree-util
+fi
+`
+
+const fishInit = `# worktree-util shell integration
+function wt
+    set -l tmpdir /tmp
+    set -q TMPDIR; and set tmpdir $TMPDIR
+    set -l cdfile (mktemp "$tmpdir/worktree-util-cd.XXXXXX"); or return 1
+    command worktree-util --cd-file $cdfile $argv
+    set -l exit_status $status
+    if test -s $cdfile
+        set -l target (cat $cdfile)
+        if test -d "$target"
+            cd $target
+        end
+    end
+    rm -f $cdfile
+    return $exit_status
+end
+
+function __worktree_util_complete
+    set -l tokens (commandline -opc)
+    set -l current (commandline -ct)
+    command worktree-util __complete $tokens[2..-1] "$current"
+end
+complete -c wt -f -a '(__worktree_util_complete)'
+complete -c worktree-util -f -a '(__worktree_util_complete)'
+`
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteShellInit(t *testing.T) {
+	for _, shell := range supportedShells {
+		t.Run(shell, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeShellInit(&buf, shell); err != nil {
+				t.Fatalf("writeShellInit(%q) error = %v", shell, err)
+			}
+			script := buf.String()
+			for _, want := range []string{"--cd-file", "mktemp", "__complete"} {
+				if !strings.Contains(script, want) {
+					t.Errorf("%s script should contain %q", shell, want)
+				}
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := writeShellInit(&buf, "tcsh"); err == nil {
+		t.Error("writeShellInit() should reject unsupported shells")
+	}
+}
+
+func TestCompletionCandidates_Static(t *testing.T) {
+	tests := []struct {
+		name     string
+		words    []string
+		expected []string
+	}{
+		{
+			name:     "subcommand prefix",
+			words:    []string{"ch"},
+			expected: []string{"checkout"},
+		},
+		{
+			name:     "config subcommands",
+			words:    []string{"config", "s"},
+			expected: []string{"set"},
+		},
+		{
+			name:     "config keys",
+			words:    []string{"config", "get", ""},
+			expected: []string{"worktree_dir", "copy_files", "preferred_remotes", "fetch_remotes", "fetch_on_checkout", "delete_branch", "delete_remote_branch", "pull_request_refs"},
+		},
+		{
+			name:     "config set keys",
+			words:    []string{"config", "set", "d"},
+			expected: []string{"delete_branch", "delete_remote_branch"},
+		},
+		{
+			name:     "list formats",
+			words:    []string{"ls", "--format", "p"},
+			expected: []string{"porcelain"},
+		},
+		{
+			name:     "flags",
+			words:    []string{"rm", "feature", "--d"},
+			expected: []string{"--delete-branch", "--delete-remote-branch", "--dry-run"},
+		},
+		{
+			name:     "shells",
+			words:    []string{"shell-init", "z"},
+			expected: []string{"zsh"},
+		},
+		{
+			name:     "no completion for add branch name",
+			words:    []string{"add", ""},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := completionCandidates(NewRepository("/repo", newFakeRunner()), tt.words)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("completionCandidates(%q) = %v, want %v", tt.words, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCompletionCandidates_Repository(t *testing.T) {
+	dir := newTestRepo(t)
+	runGit(t, dir, "branch", "feature")
+	runGit(t, dir, "tag", "v1.0.0")
+	runGit(t, dir, "worktree", "add", "-q", dir+"/.worktrees/feature", "feature")
+	repo := NewRepository(dir, ExecRunner{})
+
+	if result := completionCandidates(repo, []string{"checkout", "f"}); !reflect.DeepEqual(result, []string{"feature"}) {
+		t.Errorf("checkout completion = %v, want [feature]", result)
+	}
+	if result := completionCandidates(repo, []string{"checkout", "v"}); !reflect.DeepEqual(result, []string{"v1.0.0"}) {
+		t.Errorf("checkout completion = %v, want tag [v1.0.0]", result)
+	}
+	if result := completionCandidates(repo, []string{"remove", ""}); !reflect.DeepEqual(result, []string{"feature"}) {
+		t.Errorf("remove completion = %v, want [feature] (main worktree excluded)", result)
+	}
+}
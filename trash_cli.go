@@ -0,0 +1,171 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// trashCommands are the subcommands of the trash command
+var trashCommands = []string{"list", "restore", "purge"}
+
+// HandleTrashCommand lists, restores and purges worktrees removed into the trash
+func HandleTrashCommand(args []string) {
+	if len(args) == 0 {
+		args = []string{"list"}
+	}
+
+	switch args[0] {
+	case "list":
+		entries, err := currentRepository().ListTrash()
+		if err != nil {
+			fail(exitError, "%v", err)
+		}
+		if err := writeTrashList(os.Stdout, entries, time.Now()); err != nil {
+			fail(exitError, "%v", err)
+		}
+	case "restore":
+		handleTrashRestore(args[1:])
+	case "purge":
+		handleTrashPurge(args[1:])
+	case "-h", "--help", "help":
+		printTrashHelp()
+	default:
+		printTrashHelp()
+		os.Exit(exitUsage)
+	}
+}
+
+func printTrashHelp() {
+	fmt.Println("Usage: worktree-util trash [list]")
+	fmt.Println("       worktree-util trash restore <id> [--cd]")
+	fmt.Println("       worktree-util trash purge <id>...|--all [--yes]")
+	fmt.Println("\nWorktrees removed with 'remove --trash' (or 't' in the TUI delete")
+	fmt.Println("confirmation) keep their commit and uncommitted changes, untracked files")
+	fmt.Println("included, under refs/worktree-util/trash/<branch>/<timestamp>-<short sha>.")
+	fmt.Println("\nSubcommands:")
+	fmt.Println("  list               Show trashed worktrees, newest first")
+	fmt.Println("  restore <id>       Recreate the worktree at its old path with its changes")
+	fmt.Println("  purge <id>...      Delete trash entries for good")
+	fmt.Println("\nOptions:")
+	fmt.Println("  --cd               Change to the restored worktree (requires the shell wrapper)")
+	fmt.Println("  --all              Purge every trash entry")
+	fmt.Println("  --yes              Do not ask for confirmation")
+}
+
+// writeTrashList prints trash entries as an aligned table
+func writeTrashList(w io.Writer, entries []TrashEntry, now time.Time) error {
+	if len(entries) == 0 {
+		_, err := fmt.Fprintln(w, "Trash is empty")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tREMOVED\tPATH\tCHANGES")
+	for _, entry := range entries {
+		changes := "none"
+		if entry.HasChanges {
+			changes = "saved"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", entry.ID, relativeTime(entry.Date, now), entry.Path, changes)
+	}
+	return tw.Flush()
+}
+
+func handleTrashRestore(args []string) {
+	fs := flag.NewFlagSet("trash restore", flag.ContinueOnError)
+	cd := fs.Bool("cd", false, "change the shell to the worktree (requires the shell wrapper)")
+	fs.Usage = printTrashHelp
+
+	positional, err := parseCommandArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return
+		}
+		os.Exit(exitUsage)
+	}
+	if len(positional) != 1 {
+		printTrashHelp()
+		os.Exit(exitUsage)
+	}
+
+	repo := currentRepository()
+	entry, err := repo.FindTrashEntry(positional[0])
+	if err != nil {
+		fail(exitError, "%v", err)
+	}
+	if err := repo.RestoreTrash(entry); err != nil {
+		fail(exitError, "%v", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "✓ Worktree restored: %s\n", entry.Path)
+	fmt.Println(entry.Path)
+
+	if *cd {
+		if err := writeCdPath(entry.Path); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to write cd path: %v\n", err)
+		}
+	}
+}
+
+func handleTrashPurge(args []string) {
+	fs := flag.NewFlagSet("trash purge", flag.ContinueOnError)
+	all := fs.Bool("all", false, "purge every trash entry")
+	yes := fs.Bool("yes", false, "do not ask for confirmation")
+	fs.Usage = printTrashHelp
+
+	ids, err := parseCommandArgs(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return
+		}
+		os.Exit(exitUsage)
+	}
+	if len(ids) == 0 && !*all {
+		printTrashHelp()
+		os.Exit(exitUsage)
+	}
+
+	if err := purgeTrash(currentRepository(), os.Stdout, os.Stdin, ids, *all, *yes); err != nil {
+		fail(exitError, "%v", err)
+	}
+}
+
+// purgeTrash deletes the given trash entries, or all of them, after
+// confirmation on in (unless yes)
+func purgeTrash(repo *Repository, w io.Writer, in io.Reader, ids []string, all, yes bool) error {
+	if all {
+		entries, err := repo.ListTrash()
+		if err != nil {
+			return err
+		}
+		ids = nil
+		for _, entry := range entries {
+			ids = append(ids, entry.ID)
+		}
+	} else {
+		for _, id := range ids {
+			if _, err := repo.FindTrashEntry(id); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(ids) == 0 {
+		fmt.Fprintln(w, "Trash is empty")
+		return nil
+	}
+
+	if !yes && !confirm(w, in, fmt.Sprintf("Purge %d trash entry(ies) for good?", len(ids))) {
+		return fmt.Errorf("aborted")
+	}
+
+	if err := repo.PurgeTrash(ids...); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "✓ Purged %d trash entry(ies)\n", len(ids))
+	return nil
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTrashWorktree_RestoreChanges(t *testing.T) {
+	repo, wt := newDirtyWorktree(t)
+
+	id, err := repo.RemoveWorktreeWithOptions(wt.Path, RemoveOptions{Trash: true})
+	if err != nil {
+		t.Fatalf("RemoveWorktreeWithOptions() error = %v", err)
+	}
+	if !strings.HasPrefix(id, "feature/") {
+		t.Errorf("trash id = %q, want feature/<timestamp>", id)
+	}
+	if _, err := os.Stat(wt.Path); !os.IsNotExist(err) {
+		t.Error("the worktree should be removed")
+	}
+	if stashes := runGit(t, repo.Dir, "stash", "list"); stashes != "" {
+		t.Errorf("trashing should leave the stash list alone, got %q", stashes)
+	}
+
+	entries, err := repo.ListTrash()
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("ListTrash() = %v, %v, want one entry", entries, err)
+	}
+	entry := entries[0]
+	if entry.ID != id || entry.Branch != "feature" || entry.Path != wt.Path || !entry.HasChanges {
+		t.Errorf("ListTrash() = %+v", entry)
+	}
+
+	// The branch is recreated when it was deleted after trashing
+	runGit(t, repo.Dir, "branch", "-D", "feature")
+	if err := repo.RestoreTrash(entry); err != nil {
+		t.Fatalf("RestoreTrash() error = %v", err)
+	}
+	if branch := runGit(t, wt.Path, "rev-parse", "--abbrev-ref", "HEAD"); branch != "feature" {
+		t.Errorf("restored worktree is on %q, want feature", branch)
+	}
+	if data, _ := os.ReadFile(filepath.Join(wt.Path, "README.md")); string(data) != "changed\n" {
+		t.Errorf("README.md = %q, want the modified content", data)
+	}
+	if _, err := os.Stat(filepath.Join(wt.Path, "notes.txt")); err != nil {
+		t.Error("untracked files should be restored")
+	}
+	if entries, _ := repo.ListTrash(); len(entries) != 0 {
+		t.Errorf("a restored entry should leave the trash, got %v", entries)
+	}
+}
+
+func TestTrashWorktree_CleanDetached(t *testing.T) {
+	dir := newTestRepo(t)
+	runGit(t, dir, "config", "user.name", "Test")
+	runGit(t, dir, "config", "user.email", "test@example.com")
+	path := filepath.Join(dir, ".worktrees", "commit")
+	runGit(t, dir, "worktree", "add", "-q", "--detach", path, "HEAD")
+	commit := runGit(t, dir, "rev-parse", "HEAD")
+	repo := NewRepository(dir, ExecRunner{})
+
+	id, err := repo.RemoveWorktreeWithOptions(path, RemoveOptions{Trash: true})
+	if err != nil {
+		t.Fatalf("RemoveWorktreeWithOptions() error = %v", err)
+	}
+	entry, err := repo.FindTrashEntry(id)
+	if err != nil {
+		t.Fatalf("FindTrashEntry() error = %v", err)
+	}
+	if entry.Branch != "detached" || entry.HasChanges || entry.Commit != commit {
+		t.Errorf("FindTrashEntry() = %+v, want a clean detached entry at %s", entry, commit)
+	}
+
+	if err := repo.RestoreTrash(entry); err != nil {
+		t.Fatalf("RestoreTrash() error = %v", err)
+	}
+	if head := runGit(t, path, "rev-parse", "HEAD"); head != commit {
+		t.Errorf("restored HEAD = %v, want %v", head, commit)
+	}
+}
+
+func TestTrashWorktree_SameSecond(t *testing.T) {
+	original := trashTime
+	t.Cleanup(func() { trashTime = original })
+	trashTime = func() time.Time { return time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC) }
+
+	dir := newTestRepo(t)
+	runGit(t, dir, "config", "user.name", "Test")
+	runGit(t, dir, "config", "user.email", "test@example.com")
+	runGit(t, dir, "tag", "v1")
+	runGit(t, dir, "commit", "-q", "--allow-empty", "-m", "second commit")
+	runGit(t, dir, "tag", "v2")
+	repo := NewRepository(dir, ExecRunner{})
+
+	var ids []string
+	for _, tag := range []string{"v1", "v2"} {
+		path := filepath.Join(dir, ".worktrees", "tag-"+tag)
+		runGit(t, dir, "worktree", "add", "-q", "--detach", path, tag)
+		if err := os.WriteFile(filepath.Join(path, "notes-"+tag+".txt"), []byte("todo\n"), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		id, err := repo.RemoveWorktreeWithOptions(path, RemoveOptions{Trash: true})
+		if err != nil {
+			t.Fatalf("RemoveWorktreeWithOptions(%s) error = %v", path, err)
+		}
+		ids = append(ids, id)
+	}
+
+	if ids[0] == ids[1] || !strings.HasPrefix(ids[0], "detached/20261016-120000-") {
+		t.Errorf("trash ids = %v, want distinct detached/<timestamp>-<sha> ids", ids)
+	}
+	entries, err := repo.ListTrash()
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("ListTrash() = %v, %v, want both worktrees", entries, err)
+	}
+	for _, entry := range entries {
+		if !entry.HasChanges {
+			t.Errorf("entry %s lost its untracked file", entry.ID)
+		}
+	}
+}
+
+func TestParseTrashRefs(t *testing.T) {
+	output := "refs/worktree-util/trash/feature/login/20261016-120000-aaaaaaa\x001791000000\x00aaa bbb ccc\x00On feature/login: worktree-util trash: /repo/.worktrees/feature-login\n" +
+		"refs/worktree-util/trash/detached/20261015-090000-ddddddd\x001790900000\x00ddd\x00On detached: worktree-util trash: /repo/.worktrees/commit-ddd\n"
+
+	entries := parseTrashRefs(output)
+	if len(entries) != 2 {
+		t.Fatalf("parseTrashRefs() returned %d entries, want 2", len(entries))
+	}
+	first := entries[0]
+	if first.ID != "feature/login/20261016-120000-aaaaaaa" || first.Branch != "feature/login" || first.Commit != "aaa" || !first.HasChanges {
+		t.Errorf("parseTrashRefs()[0] = %+v", first)
+	}
+	if first.Path != "/repo/.worktrees/feature-login" || !first.Date.Equal(time.Unix(1791000000, 0)) {
+		t.Errorf("parseTrashRefs()[0] = %+v", first)
+	}
+	if second := entries[1]; second.Branch != "detached" || second.HasChanges {
+		t.Errorf("parseTrashRefs()[1] = %+v", second)
+	}
+}
+
+func TestWriteTrashList(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	var out bytes.Buffer
+	if err := writeTrashList(&out, nil, now); err != nil || !strings.Contains(out.String(), "Trash is empty") {
+		t.Errorf("writeTrashList() = %q, %v", out.String(), err)
+	}
+
+	out.Reset()
+	entries := []TrashEntry{{ID: "feature/20261016-100000", Path: "/repo/.worktrees/feature", Date: now.Add(-2 * time.Hour), HasChanges: true}}
+	if err := writeTrashList(&out, entries, now); err != nil {
+		t.Fatalf("writeTrashList() error = %v", err)
+	}
+	for _, want := range []string{"feature/20261016-100000", "2 hours ago", "/repo/.worktrees/feature", "saved"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("writeTrashList() should contain %q, got:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestPurgeTrash(t *testing.T) {
+	repo, wt := newDirtyWorktree(t)
+	id, err := repo.RemoveWorktreeWithOptions(wt.Path, RemoveOptions{Trash: true})
+	if err != nil {
+		t.Fatalf("RemoveWorktreeWithOptions() error = %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := purgeTrash(repo, &out, strings.NewReader("n\n"), []string{id}, false, false); err == nil {
+		t.Error("purgeTrash() should abort when confirmation is declined")
+	}
+	if err := purgeTrash(repo, &out, strings.NewReader(""), []string{"missing/20260101-000000"}, false, true); err == nil {
+		t.Error("purgeTrash() should fail for an unknown entry")
+	}
+	if err := purgeTrash(repo, &out, strings.NewReader(""), nil, true, true); err != nil {
+		t.Fatalf("purgeTrash() error = %v", err)
+	}
+	if entries, _ := repo.ListTrash(); len(entries) != 0 {
+		t.Errorf("the trash should be empty, got %v", entries)
+	}
+}
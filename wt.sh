@@ -1,19 +1,25 @@
 #!/bin/bash
 # Shell wrapper for worktree-util to enable directory changing
-# Add this function to your ~/.bashrc or ~/.zshrc
+# Add this function to your ~/.bashrc or ~/.zshrc, or use
+#   eval "$(worktree-util shell-init bash)"   (or zsh / fish)
+# which also sets up tab completion.
 #
 # All error handling and logic is in the binary.
-# This wrapper just reads the temp file and cd's if it exists.
+# This wrapper creates a private temp file (mktemp, mode 0600), passes it
+# with --cd-file and cd's into the path the binary wrote there, if any.
 
 wt() {
-    worktree-util "$@"
-    local tmpfile="/tmp/worktree-util-cd"
-    if [ -f "$tmpfile" ]; then
-        local target=$(cat "$tmpfile")
-        rm -f "$tmpfile"
+    local cdfile
+    cdfile=$(mktemp "${TMPDIR:-/tmp}/worktree-util-cd.XXXXXX") || return 1
+    worktree-util --cd-file "$cdfile" "$@"
+    local exit_status=$?
+    if [ -s "$cdfile" ]; then
+        local target
+        target=$(cat "$cdfile")
         if [ -d "$target" ]; then
             cd "$target"
         fi
     fi
+    rm -f "$cdfile"
+    return $exit_status
 }
-